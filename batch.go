@@ -0,0 +1,185 @@
+package webpagetest
+
+import (
+	"context"
+	"time"
+)
+
+// BatchOptions controls how RunBatch submits and polls tests
+type BatchOptions struct {
+	// Upper limit of tests per location that are queued or running at once.
+	// Actual limit is derived from number of idle agents and pending tests at
+	// location, as reported by GetLocations. 0 means no upper limit
+	MaxPerLocation int
+	// Interval between status polls of all outstanding tests (10s)
+	PollInterval time.Duration
+	// Number of consecutive polls of test, that may fail with network or
+	// server error before test is given up (3), negative means that any error
+	// gives up test. Test keeps running on server in any case
+	MaxPollErrors int
+
+	// OnStatus is called for every status poll of every test, index is
+	// position of test in batch
+	OnStatus func(index int, event StatusEvent)
+}
+
+// BatchResult is outcome of one test in batch
+type BatchResult struct {
+	Settings TestSettings
+	// ID of test, empty if test was not started
+	TestID string
+	Result *ResultData
+	Err    error
+}
+
+// RunBatch will run all given tests, respecting per-location concurrency limits,
+// and will wait for all of them to complete. Results are returned in the same
+// order as tests. Error is returned only if batch can't be run at all, errors of
+// individual tests are in BatchResult.Err
+func (w *WebPageTest) RunBatch(ctx context.Context, tests []TestSettings, opts BatchOptions) ([]BatchResult, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 10 * time.Second
+	}
+	if opts.MaxPollErrors == 0 {
+		opts.MaxPollErrors = 3
+	}
+
+	locations, err := w.GetLocationsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limits := locationLimits(locations, opts.MaxPerLocation)
+
+	results := make([]BatchResult, len(tests))
+	pollErrors := make([]int, len(tests))
+	queues := make(map[string][]int)
+	var order []string
+	for idx, settings := range tests {
+		results[idx].Settings = settings
+		// Location as it is known to GetLocations, "Dulles:Chrome.Cable" becomes "Dulles"
+		location, _, _ := settings.location()
+		if _, ok := queues[location]; !ok {
+			order = append(order, location)
+		}
+		queues[location] = append(queues[location], idx)
+	}
+
+	started := time.Now()
+	running := make(map[string][]int)
+	for {
+		// Submit as much as locations allow
+		for _, location := range order {
+			limit, ok := limits[location]
+			if !ok {
+				// Unknown location, runtest.php will most likely reject it anyway
+				limit = 1
+			}
+			for len(queues[location]) > 0 && len(running[location]) < limit {
+				idx := queues[location][0]
+				queues[location] = queues[location][1:]
+
+				test, err := w.RunTestContext(ctx, tests[idx])
+				if err != nil {
+					results[idx].Err = err
+					continue
+				}
+				results[idx].TestID = test.TestID
+				running[location] = append(running[location], idx)
+			}
+		}
+
+		if len(running) == 0 {
+			return results, nil
+		}
+
+		timer := time.NewTimer(opts.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			for _, indexes := range running {
+				for _, idx := range indexes {
+					results[idx].Err = ctx.Err()
+				}
+			}
+			for _, indexes := range queues {
+				for _, idx := range indexes {
+					results[idx].Err = ctx.Err()
+				}
+			}
+			return results, ctx.Err()
+		case <-timer.C:
+		}
+
+		// Poll all outstanding tests
+		for _, location := range order {
+			var stillRunning []int
+			for _, idx := range running[location] {
+				if w.pollBatchItem(ctx, idx, &results[idx], &pollErrors[idx], started, opts) {
+					continue
+				}
+				stillRunning = append(stillRunning, idx)
+			}
+			if len(stillRunning) == 0 {
+				delete(running, location)
+				continue
+			}
+			running[location] = stillRunning
+		}
+	}
+}
+
+// pollBatchItem polls status of one test in batch and fetches its result if
+// test is completed. Returns true if test is done, successfully or not.
+// Transient errors are counted in pollErrors and test is polled again later
+func (w *WebPageTest) pollBatchItem(ctx context.Context, idx int, item *BatchResult, pollErrors *int, started time.Time, opts BatchOptions) bool {
+	status, err := w.GetTestStatusContext(ctx, item.TestID)
+	if err != nil {
+		return w.batchItemFailed(ctx, item, pollErrors, opts, err)
+	}
+	event := newStatusEvent(item.TestID, status, started)
+	if opts.OnStatus != nil {
+		opts.OnStatus(idx, event)
+	}
+	if !event.IsComplete() {
+		*pollErrors = 0
+		return false
+	}
+
+	result, err := w.GetTestResultContext(ctx, item.TestID)
+	if err != nil {
+		return w.batchItemFailed(ctx, item, pollErrors, opts, err)
+	}
+	item.Result, item.Err = result, nil
+	return true
+}
+
+// batchItemFailed records error of test in batch, returns false if error is
+// transient and test should be polled again
+func (w *WebPageTest) batchItemFailed(ctx context.Context, item *BatchResult, pollErrors *int, opts BatchOptions, err error) bool {
+	item.Err = err
+	if ctx.Err() == nil && isTransient(err) && *pollErrors < opts.MaxPollErrors {
+		*pollErrors++
+		return false
+	}
+	return true
+}
+
+// locationLimits calculates how many tests we can put at every location at once:
+// number of idle agents minus tests that are already waiting in queue, but at least one
+func locationLimits(locations *Locations, max int) map[string]int {
+	limits := make(map[string]int)
+	for _, group := range *locations {
+		for _, location := range group {
+			queued := location.PendingTests["Total"] - location.PendingTests["Testing"]
+			limit := location.PendingTests["Idle"] - queued
+			if limit < 1 {
+				limit = 1
+			}
+			if max > 0 && limit > max {
+				limit = max
+			}
+			limits[location.Location] = limit
+		}
+	}
+	return limits
+}
@@ -0,0 +1,81 @@
+package webpagetest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBatch(t *testing.T) {
+	result, err := ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+
+	var mu sync.Mutex
+	polls := make(map[string]int)
+	running, maxRunning := 0, 0
+	wpt := newTestClient(t, testRoutes{
+		"/getLocations.php": respond(`{"statusCode": 200, "statusText": "Ok", "data": {
+			"Dulles": {"location": "Dulles", "group": "USA", "PendingTests": {"Total": 3, "Testing": 1, "Idle": 4}}
+		}}`),
+		"/runtest.php": func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			r.ParseForm()
+			if r.Form.Get("url") == "invalid" {
+				w.Write([]byte(`{"statusCode": 400, "statusText": "Invalid URL"}`))
+				return
+			}
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			fmt.Fprintf(w, `{"statusCode": 200, "data": {"testId": "id-%s"}}`, r.Form.Get("url"))
+		},
+		"/testStatus.php": func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			id := r.URL.Query().Get("test")
+			polls[id]++
+			code := 100
+			if polls[id] >= 2 {
+				code = 200
+				running--
+			}
+			fmt.Fprintf(w, `{"statusCode": %d, "data": {"statusCode": %d}}`, code, code)
+		},
+		"/jsonResult.php": respond(string(result)),
+	})
+
+	tests := []TestSettings{
+		{URL: "a", Location: "Dulles:Chrome"},
+		{URL: "invalid", Location: "Dulles:Chrome"},
+		{URL: "b", Location: "Dulles:Chrome"},
+		{URL: "c", Location: "Dulles:Firefox"},
+		{URL: "d", Location: "Dulles:Chrome"},
+		{URL: "e", Location: "Dulles.Cable"},
+	}
+	results, err := wpt.RunBatch(context.Background(), tests, BatchOptions{PollInterval: time.Millisecond})
+	assert.Nil(t, err)
+	assert.Len(t, results, len(tests))
+
+	for idx, item := range results {
+		assert.Equal(t, tests[idx].URL, item.Settings.URL)
+		if tests[idx].URL == "invalid" {
+			assert.NotNil(t, item.Err)
+			continue
+		}
+		assert.Nil(t, item.Err)
+		assert.Equal(t, "id-"+tests[idx].URL, item.TestID)
+		assert.NotNil(t, item.Result)
+	}
+	// 4 idle agents with 2 tests in queue leaves 2 slots
+	assert.Equal(t, 2, maxRunning)
+}
@@ -0,0 +1,340 @@
+package webpagetest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Views of test, as they are named in WebPageTest json
+const (
+	FirstView  = "firstView"
+	RepeatView = "repeatView"
+)
+
+// Budget is a set of performance budgets for a test. Budgets can be defined in
+// YAML or JSON:
+//
+//	medianMetric: SpeedIndex
+//	metrics:
+//	  - metric: loadTime
+//	    max: 3000
+//	  - metric: userTime.hero
+//	    max: 1500
+//	    step: 2
+//	    view: firstView
+//	resources:
+//	  - type: js
+//	    maxBytes: 300000
+//	    maxRequests: 20
+//	  - type: total
+//	    maxBytes: 1500000
+type Budget struct {
+	// Metric to pick median run by, empty means median metric of test (see GetMedianRun)
+	MedianMetric string           `yaml:"medianMetric,omitempty" json:"medianMetric,omitempty"`
+	Metrics      []MetricBudget   `yaml:"metrics,omitempty" json:"metrics,omitempty"`
+	Resources    []ResourceBudget `yaml:"resources,omitempty" json:"resources,omitempty"`
+}
+
+// MetricBudget is threshold for any metric supported by TestStep.Metric
+type MetricBudget struct {
+	Metric string   `yaml:"metric" json:"metric"`
+	Max    *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+	Min    *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	// 1-based step, 0 means every step
+	Step int `yaml:"step,omitempty" json:"step,omitempty"`
+	// Event name of step, takes precedence over Step if set
+	EventName string `yaml:"eventName,omitempty" json:"eventName,omitempty"`
+	// FirstView or RepeatView, empty means both
+	View string `yaml:"view,omitempty" json:"view,omitempty"`
+}
+
+// ResourceBudget is threshold for bytes and requests of resource type from
+// TestStep.Breakdown, like "js" or "image", or "total" for all requests
+type ResourceBudget struct {
+	Type        string `yaml:"type" json:"type"`
+	MaxBytes    *int   `yaml:"maxBytes,omitempty" json:"maxBytes,omitempty"`
+	MaxRequests *int   `yaml:"maxRequests,omitempty" json:"maxRequests,omitempty"`
+	// 1-based step, 0 means every step
+	Step int `yaml:"step,omitempty" json:"step,omitempty"`
+	// Event name of step, takes precedence over Step if set
+	EventName string `yaml:"eventName,omitempty" json:"eventName,omitempty"`
+	// FirstView or RepeatView, empty means both
+	View string `yaml:"view,omitempty" json:"view,omitempty"`
+}
+
+// BudgetResult is result of one threshold check for one step and view
+type BudgetResult struct {
+	// Metric name, or "<type>.bytes" and "<type>.requests" for resources
+	Name string
+	// 1-based step
+	Step      int
+	EventName string
+	View      string
+	// Value of median run
+	Value float64
+	// Limit and its kind: "max" or "min"
+	Limit  float64
+	Kind   string
+	Passed bool
+	// Missing is true if step has no such metric, step has no successful
+	// runs or result has no step matching budget, it is never passed
+	Missing bool
+}
+
+// String gives human readable description of result
+func (r BudgetResult) String() string {
+	status := "ok"
+	if !r.Passed {
+		status = "FAIL"
+	}
+	if r.Missing {
+		return fmt.Sprintf("%s: %s %s is missing", status, r.scope(), r.Name)
+	}
+	return fmt.Sprintf("%s: %s %s = %v (%s %v)", status, r.scope(), r.Name, r.Value, r.Kind, r.Limit)
+}
+
+// scope gives step and view of result, like "step 1 firstView" or "step \"Checkout\""
+func (r BudgetResult) scope() string {
+	scope := fmt.Sprintf("step %d", r.Step)
+	if r.Step == 0 && r.EventName != "" {
+		scope = fmt.Sprintf("step %q", r.EventName)
+	}
+	if r.View != "" {
+		scope += " " + r.View
+	}
+	return scope
+}
+
+// BudgetReport is result of evaluation of budget against test result
+type BudgetReport struct {
+	Passed  bool
+	Results []BudgetResult
+}
+
+// Failures will return only failed checks
+func (r *BudgetReport) Failures() []BudgetResult {
+	var failures []BudgetResult
+	for _, result := range r.Results {
+		if !result.Passed {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// ParseBudget will parse budget from YAML or JSON and validate it
+func ParseBudget(data []byte) (*Budget, error) {
+	var budget Budget
+	if err := yaml.Unmarshal(data, &budget); err != nil {
+		return nil, err
+	}
+	if err := budget.validate(); err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// LoadBudget will read budget from YAML or JSON file
+func LoadBudget(path string) (*Budget, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBudget(data)
+}
+
+func (b *Budget) validate() error {
+	if len(b.Metrics) == 0 && len(b.Resources) == 0 {
+		return errors.New("budget is empty")
+	}
+	for idx, metric := range b.Metrics {
+		if metric.Metric == "" {
+			return fmt.Errorf("metrics[%d]: metric is required", idx)
+		}
+		if metric.Max == nil && metric.Min == nil {
+			return fmt.Errorf("metrics[%d] (%s): max or min is required", idx, metric.Metric)
+		}
+		if err := validateBudgetScope(metric.Step, metric.View); err != nil {
+			return fmt.Errorf("metrics[%d] (%s): %v", idx, metric.Metric, err)
+		}
+	}
+	for idx, resource := range b.Resources {
+		if resource.Type == "" {
+			return fmt.Errorf("resources[%d]: type is required", idx)
+		}
+		if resource.MaxBytes == nil && resource.MaxRequests == nil {
+			return fmt.Errorf("resources[%d] (%s): maxBytes or maxRequests is required", idx, resource.Type)
+		}
+		if err := validateBudgetScope(resource.Step, resource.View); err != nil {
+			return fmt.Errorf("resources[%d] (%s): %v", idx, resource.Type, err)
+		}
+	}
+	return nil
+}
+
+func validateBudgetScope(step int, view string) error {
+	if step < 0 {
+		return fmt.Errorf("invalid step %d", step)
+	}
+	if view != "" && view != FirstView && view != RepeatView {
+		return fmt.Errorf("invalid view %q, should be %s or %s", view, FirstView, RepeatView)
+	}
+	return nil
+}
+
+// budgetView is one step of median run to check
+type budgetView struct {
+	step int
+	view string
+	data *TestStep
+	// Step has no median run, like when every run of it failed
+	missing bool
+}
+
+// Evaluate will check budget against median runs of every step of result
+func (b *Budget) Evaluate(rd *ResultData) (*BudgetReport, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	var views []budgetView
+	names := rd.StepNames()
+	for step := 0; step < rd.numSteps(); step++ {
+		run, err := rd.GetMedianRun(step, b.MedianMetric)
+		if err != nil {
+			// Budgets of step without median run fail as missing data,
+			// other steps are still checked
+			views = append(views, budgetView{step + 1, FirstView, &TestStep{EventName: names[step]}, true})
+			continue
+		}
+		views = append(views, budgetView{step + 1, FirstView, &run.FirstView.Steps[step], false})
+		if step < len(run.RepeatView.Steps) {
+			views = append(views, budgetView{step + 1, RepeatView, &run.RepeatView.Steps[step], false})
+		}
+	}
+	if len(views) == 0 {
+		return nil, errors.New("result has no steps")
+	}
+
+	report := &BudgetReport{Passed: true}
+	metricMatched := make([]bool, len(b.Metrics))
+	resourceMatched := make([]bool, len(b.Resources))
+	for _, view := range views {
+		for idx, metric := range b.Metrics {
+			if !view.matches(metric.Step, metric.EventName, metric.View) {
+				continue
+			}
+			metricMatched[idx] = true
+			value, ok := view.metric(metric.Metric)
+			if metric.Max != nil {
+				report.add(view.result(metric.Metric, value, ok, "max", *metric.Max))
+			}
+			if metric.Min != nil {
+				report.add(view.result(metric.Metric, value, ok, "min", *metric.Min))
+			}
+		}
+
+		for idx, resource := range b.Resources {
+			if !view.matches(resource.Step, resource.EventName, resource.View) {
+				continue
+			}
+			resourceMatched[idx] = true
+			bytes, requests, ok := view.resource(resource.Type)
+			if resource.MaxBytes != nil {
+				report.add(view.result(resource.Type+".bytes", bytes, ok, "max", float64(*resource.MaxBytes)))
+			}
+			if resource.MaxRequests != nil {
+				report.add(view.result(resource.Type+".requests", requests, ok, "max", float64(*resource.MaxRequests)))
+			}
+		}
+	}
+
+	// Budget for step, that is not in result (like a typo in event name or
+	// step removed from script), must not pass silently
+	for idx, metric := range b.Metrics {
+		if metricMatched[idx] {
+			continue
+		}
+		view := missingView(metric.Step, metric.EventName, metric.View)
+		if metric.Max != nil {
+			report.add(view.result(metric.Metric, 0, false, "max", *metric.Max))
+		}
+		if metric.Min != nil {
+			report.add(view.result(metric.Metric, 0, false, "min", *metric.Min))
+		}
+	}
+	for idx, resource := range b.Resources {
+		if resourceMatched[idx] {
+			continue
+		}
+		view := missingView(resource.Step, resource.EventName, resource.View)
+		if resource.MaxBytes != nil {
+			report.add(view.result(resource.Type+".bytes", 0, false, "max", float64(*resource.MaxBytes)))
+		}
+		if resource.MaxRequests != nil {
+			report.add(view.result(resource.Type+".requests", 0, false, "max", float64(*resource.MaxRequests)))
+		}
+	}
+	return report, nil
+}
+
+// missingView is a view for budget, that matched no step of result
+func missingView(step int, eventName, view string) budgetView {
+	return budgetView{step, view, &TestStep{EventName: eventName}, true}
+}
+
+func (r *BudgetReport) add(result BudgetResult) {
+	r.Results = append(r.Results, result)
+	if !result.Passed {
+		r.Passed = false
+	}
+}
+
+func (v budgetView) matches(step int, eventName, view string) bool {
+	if eventName != "" {
+		return eventName == v.data.EventName && (view == "" || view == v.view)
+	}
+	return (step == 0 || step == v.step) && (view == "" || view == v.view)
+}
+
+func (v budgetView) metric(name string) (float64, bool) {
+	if v.missing {
+		return 0, false
+	}
+	return v.data.Metric(name)
+}
+
+func (v budgetView) resource(name string) (bytes, requests float64, ok bool) {
+	if v.missing {
+		return 0, 0, false
+	}
+	if name == "total" {
+		return float64(v.data.BytesIn), float64(v.data.RequestsFull), true
+	}
+	breakdown, ok := v.data.Breakdown[name]
+	return float64(breakdown.Bytes), float64(breakdown.Requests), ok
+}
+
+func (v budgetView) result(name string, value float64, ok bool, kind string, limit float64) BudgetResult {
+	result := BudgetResult{
+		Name:      name,
+		Step:      v.step,
+		EventName: v.data.EventName,
+		View:      v.view,
+		Value:     value,
+		Limit:     limit,
+		Kind:      kind,
+		Missing:   !ok,
+	}
+	if ok {
+		if kind == "max" {
+			result.Passed = value <= limit
+		} else {
+			result.Passed = value >= limit
+		}
+	}
+	return result
+}
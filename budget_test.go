@@ -0,0 +1,99 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testBudget = `
+metrics:
+  - metric: loadTime
+    max: 45000
+  - metric: SpeedIndex
+    max: 5000
+    view: firstView
+  - metric: userTime.missing
+    max: 100
+    step: 2
+resources:
+  - type: js
+    maxBytes: 500000
+    maxRequests: 30
+  - type: total
+    maxRequests: 200
+    view: repeatView
+  - type: image
+    maxBytes: 100000
+    eventName: Checkout
+`
+
+func TestBudgetEvaluate(t *testing.T) {
+	budget, err := ParseBudget([]byte(testBudget))
+	assert.Nil(t, err)
+
+	report, err := budget.Evaluate(loadResult(t, "TestResultPlrAsNumber.json"))
+	assert.Nil(t, err)
+	assert.False(t, report.Passed)
+	// loadTime x2, SpeedIndex, js x2 for both views, total for repeat view
+	// and budgets for missing steps
+	assert.Len(t, report.Results, 10)
+
+	failures := report.Failures()
+	assert.Len(t, failures, 4)
+	assert.Equal(t, BudgetResult{
+		Name: "SpeedIndex", Step: 1, EventName: "Step 1", View: FirstView,
+		Value: 7984, Limit: 5000, Kind: "max",
+	}, failures[0])
+	assert.Equal(t, "js.bytes", failures[1].Name)
+	assert.Equal(t, 842832.0, failures[1].Value)
+	assert.Equal(t, "FAIL: step 1 firstView js.bytes = 842832 (max 500000)", failures[1].String())
+
+	// Budgets for steps, that are not in result, fail
+	assert.Equal(t, BudgetResult{
+		Name: "userTime.missing", Step: 2, Limit: 100, Kind: "max", Missing: true,
+	}, failures[2])
+	assert.Equal(t, `FAIL: step "Checkout" image.bytes is missing`, failures[3].String())
+}
+
+func TestParseBudget(t *testing.T) {
+	budget, err := ParseBudget([]byte(`{"medianMetric": "SpeedIndex", "metrics": [{"metric": "TTFB", "min": 1, "max": 500}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "SpeedIndex", budget.MedianMetric)
+	assert.Equal(t, 500.0, *budget.Metrics[0].Max)
+
+	for _, invalid := range []string{
+		``,
+		`metrics: [{metric: loadTime}]`,
+		`metrics: [{max: 1}]`,
+		`metrics: [{metric: loadTime, max: 1, view: cached}]`,
+		`resources: [{type: js}]`,
+	} {
+		_, err := ParseBudget([]byte(invalid))
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestBudgetEvaluateFailedStep(t *testing.T) {
+	result := loadResult(t, "TestResultPlrAsNumber.json")
+	insertStep(result, "Login")
+	for key, run := range result.Runs {
+		run.FirstView.Steps[0].Result = 404
+		run.RepeatView.Steps[0].Result = 404
+		result.Runs[key] = run
+	}
+
+	budget, err := ParseBudget([]byte("metrics:\n  - metric: loadTime\n    max: 45000\n"))
+	assert.Nil(t, err)
+	report, err := budget.Evaluate(result)
+	assert.Nil(t, err)
+	assert.False(t, report.Passed)
+	// Failed step and both views of the other step
+	assert.Len(t, report.Results, 3)
+
+	failures := report.Failures()
+	assert.Len(t, failures, 1)
+	assert.Equal(t, BudgetResult{
+		Name: "loadTime", Step: 1, EventName: "Login", View: FirstView, Limit: 45000, Kind: "max", Missing: true,
+	}, failures[0])
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -25,14 +26,21 @@ Usage:
   webpagetest status <testID> [--server=<url>]
   webpagetest cancel <testID> [--server=<url>]
   webpagetest results <testID> [--server=<url>] [--step=<stepIdx>]
+  webpagetest run [<url>] [--server=<url>] [--settings=<file>] [--wait] [--timeout=<duration>] [--step=<stepIdx>] [options]
   webpagetest -h | --help
   webpagetest --version
 
 Options:
-  -h --help         Show this screen.
-  --version         Show version.
-  --server=<url>    URL of private instance of WebPagetest Server
-  --step=<stepIdx>  Index of test step to use as source of metrics (1-based)`
+  -h --help               Show this screen.
+  --version               Show version.
+  --server=<url>          URL of private instance of WebPagetest Server
+  --step=<stepIdx>        Index of test step to use as source of metrics (1-based) or its event name
+  --settings=<file>       YAML or JSON file with TestSettings, flags override values from it,
+                          <url> can be omitted if file has it
+  --wait                  Wait for test to complete and print results
+  --timeout=<duration>    Give up waiting after this time, like 10m [default: 30m]
+
+` + settingsUsage()
 
 	arguments, _ := docopt.Parse(usage, nil, true, "WebPagetest CLI 1.0", false)
 
@@ -61,41 +69,39 @@ Options:
 	}
 
 	if arguments["results"].(bool) {
-		var step int64
-		if arguments["--step"] != nil && arguments["--step"].(string) != "" {
-			step, _ = strconv.ParseInt(arguments["--step"].(string), 10, 32)
-			fmt.Printf("Will use step #%d\n", step)
-		}
+		getResults(arguments["<testID>"].(string), parseStep(arguments))
+	}
+
+	if arguments["cancel"].(bool) {
+		cancelTest(arguments["<testID>"].(string))
+	}
 
-		getResults(arguments["<testID>"].(string), step)
-	}
-
-	// TODO: figure out how to specify all test params
-
-	// result, err := wpt.RunTest(webpagetest.TestSettings{
-	// 	URL:      "http://ngs.ru",
-	// 	Location: "74RU_wpt:Chrome",
-	// 	Runs:     3,
-	// 	// ScreenWidth:  1280,
-	// 	// ScreenHeight: 720,
-	// })
-	// if err != nil {
-	// 	fmt.Printf("Error: %v", err)
-	// 	os.Exit(2)
-	// }
-	// fmt.Printf("Result: %#v", result)
-
-	// result, err := wpt.RunTestAndWait(webpagetest.TestSettings{
-	// 	URL:          "http://ngs.ru",
-	// 	Location:     "74RU_wpt:Chrome",
-	// 	Runs:         3,
-	// 	ScreenWidth:  1280,
-	// 	ScreenHeight: 720,
-	// })
-	// if err != nil {
-	// 	fmt.Printf("Error: %v", err)
-	// 	os.Exit(2)
-	// }
+	if arguments["run"].(bool) {
+		runTest(arguments)
+	}
+}
+
+func parseStep(arguments map[string]interface{}) string {
+	var step string
+	if arguments["--step"] != nil && arguments["--step"].(string) != "" {
+		step = arguments["--step"].(string)
+		fmt.Printf("Will use step %q\n", step)
+	}
+	return step
+}
+
+// stepIndex will resolve 1-based step number or event name of step to 0-based index
+func stepIndex(result *webpagetest.ResultData, step string) (int, error) {
+	if step == "" {
+		return 0, nil
+	}
+	if number, err := strconv.ParseInt(step, 10, 32); err == nil {
+		if number < 1 {
+			return 0, fmt.Errorf("invalid step %d, steps are 1-based", number)
+		}
+		return int(number - 1), nil
+	}
+	return result.StepIndex(step)
 }
 
 // Get Locations
@@ -151,7 +157,7 @@ func cancelTest(testID string) {
 	}
 }
 
-func getResults(testID string, testStep int64) {
+func getResults(testID string, testStep string) {
 	// Test Result
 	// 161124_CC_3 - google.com
 	// 161122_K9_A - novosibirsk.n1.ru
@@ -162,7 +168,54 @@ func getResults(testID string, testStep int64) {
 		fmt.Printf("Error: %v", err)
 		os.Exit(2)
 	}
+	printResults(result, testStep)
+}
 
+// Run Test
+func runTest(arguments map[string]interface{}) {
+	url, _ := arguments["<url>"].(string)
+	settings, err := parseSettings(url, arguments)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if !arguments["--wait"].(bool) {
+		test, err := wpt.RunTest(settings)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Printf("Test ID: %s\n", test.TestID)
+		fmt.Printf("Results: %s\n", test.UserURL)
+		fmt.Printf("JSON: %s\n", test.JSONURL)
+		return
+	}
+
+	timeout, err := time.ParseDuration(arguments["--timeout"].(string))
+	if err != nil {
+		fmt.Printf("Error: invalid --timeout: %v\n", err)
+		os.Exit(2)
+	}
+	result, err := wpt.RunTestAndWaitWithOptions(context.Background(), settings, webpagetest.WaitOptions{
+		Timeout:         timeout,
+		CancelOnTimeout: true,
+		OnStatus: func(event webpagetest.StatusEvent) {
+			progress := ""
+			if event.RunsExpected > 0 {
+				progress = fmt.Sprintf(" (%d/%d runs)", event.RunsCompleted, event.RunsExpected)
+			}
+			fmt.Printf("[%v] %s: %s%s\n", event.Elapsed.Round(time.Second), event.TestID, event.StatusText, progress)
+		},
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	printResults(result, parseStep(arguments))
+}
+
+func printResults(result *webpagetest.ResultData, testStep string) {
 	fmt.Printf("ID: %v\n", result.ID)
 	fmt.Printf("URL: %v\n", result.URL)
 	fmt.Printf("Summary: %v\n", result.Summary)
@@ -198,18 +251,23 @@ func getResults(testID string, testStep int64) {
 			}
 		}
 	}
-	if testStep == 0 {
-		testStep = 1
+	step, err := stepIndex(result, testStep)
+	if err != nil {
+		fmt.Printf("Invalid step: %v\n", err)
+		return
 	}
 
-	medianRun, err := result.GetMedianRun(int(testStep-1), "loadtime")
+	medianRun, err := result.GetMedianRun(step, "")
 	if err != nil {
 		fmt.Printf("GetMedianRun failed: %v\n", err)
+		return
 	}
 	fmt.Printf("\nMedian run\n")
-	fmt.Println(stepAsTableRow(&medianRun.FirstView.Steps[testStep-1], true,
-		fmt.Sprintf("Run: #%d/%d ", medianRun.FirstView.Run, medianRun.RepeatView.Run)))
-	fmt.Println(stepAsTableRow(&medianRun.RepeatView.Steps[testStep-1], false, ""))
+	fmt.Println(stepAsTableRow(&medianRun.FirstView.Steps[step], true,
+		fmt.Sprintf("Run: #%v/%v ", medianRun.FirstView.Run, medianRun.RepeatView.Run)))
+	if step < len(medianRun.RepeatView.Steps) {
+		fmt.Println(stepAsTableRow(&medianRun.RepeatView.Steps[step], false, ""))
+	}
 }
 
 func stepAsTableRow(ts *webpagetest.TestStep, header bool, headerTitle string) string {
@@ -223,7 +281,7 @@ func stepAsTableRow(ts *webpagetest.TestStep, header bool, headerTitle string) s
 		fmt.Sprintf("Step %d ", ts.Step),
 		time.Duration(ts.LoadTime)*time.Millisecond,
 		time.Duration(ts.TTFB)*time.Millisecond,
-		time.Duration(ts.StartRender)*time.Millisecond,
+		time.Duration(ts.Render)*time.Millisecond,
 		ts.SpeedIndex,
 		time.Duration(ts.DocTime)*time.Millisecond,
 		time.Duration(ts.FullyLoaded)*time.Millisecond)
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/olegfedoseev/go-webpagetest"
+)
+
+// Every field of TestSettings (except URL) is available as a flag, named
+// after the field: FirstViewOnly is --first-view-only, ScreenWidth is
+// --screen-width=<value> and so on
+
+// settingsFlag is a command line flag for one field of TestSettings
+type settingsFlag struct {
+	name  string
+	field int
+	kind  reflect.Kind
+}
+
+func settingsFlags() []settingsFlag {
+	var flags []settingsFlag
+	settings := reflect.TypeOf(webpagetest.TestSettings{})
+	for i := 0; i < settings.NumField(); i++ {
+		field := settings.Field(i)
+		if field.Name == "URL" {
+			continue
+		}
+		flags = append(flags, settingsFlag{
+			name:  flagName(field.Name),
+			field: i,
+			kind:  field.Type.Kind(),
+		})
+	}
+	return flags
+}
+
+// flagName converts field name to flag name: "PNGScreenShot" to "png-screen-shot"
+func flagName(field string) string {
+	runes := []rune(field)
+	var name []rune
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previousLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				name = append(name, '-')
+			}
+		}
+		name = append(name, unicode.ToLower(r))
+	}
+	return string(name)
+}
+
+// settingsUsage is docopt options section for all settings flags
+func settingsUsage() string {
+	lines := []string{"Test settings options:"}
+	for _, flag := range settingsFlags() {
+		if flag.kind == reflect.Bool {
+			lines = append(lines, "  --"+flag.name)
+		} else {
+			lines = append(lines, fmt.Sprintf("  --%s=<value>", flag.name))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseSettings builds TestSettings from YAML or JSON settings file (if any) and flags,
+// flags take precedence over file
+func parseSettings(url string, arguments map[string]interface{}) (webpagetest.TestSettings, error) {
+	var settings webpagetest.TestSettings
+	if file, ok := arguments["--settings"].(string); ok && file != "" {
+		loaded, err := webpagetest.LoadTestSettings(file)
+		if err != nil {
+			return settings, err
+		}
+		settings = *loaded
+	}
+	if url != "" {
+		settings.URL = url
+	}
+	if settings.URL == "" && settings.Script == "" {
+		return settings, errors.New("<url> is required, unless --settings has URL or Script")
+	}
+
+	value := reflect.ValueOf(&settings).Elem()
+	for _, flag := range settingsFlags() {
+		argument, ok := arguments["--"+flag.name]
+		if !ok || argument == nil {
+			continue
+		}
+		field := value.Field(flag.field)
+
+		switch flag.kind {
+		case reflect.Bool:
+			if argument.(bool) {
+				field.SetBool(true)
+			}
+		case reflect.Int:
+			number, err := strconv.Atoi(argument.(string))
+			if err != nil {
+				return settings, fmt.Errorf("invalid --%s: %v", flag.name, err)
+			}
+			field.SetInt(int64(number))
+		case reflect.String:
+			field.SetString(argument.(string))
+		}
+	}
+	return settings, nil
+}
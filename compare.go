@@ -0,0 +1,270 @@
+package webpagetest
+
+import (
+	"errors"
+)
+
+// DefaultCompareMetrics are metrics that Compare uses if none are given
+var DefaultCompareMetrics = []string{
+	"TTFB", "startRender", "firstContentfulPaint", "SpeedIndex", "loadTime",
+	"fullyLoaded", "bytesIn", "requestsFull",
+}
+
+// CompareOptions are options for comparison of two results
+type CompareOptions struct {
+	// Metrics to compare, DefaultCompareMetrics if empty
+	Metrics []string
+	// Metric to pick median runs by, empty means median metric of test (see GetMedianRun)
+	MedianMetric string
+	// Compare every run of baseline with the run with the same number of candidate
+	AllRuns bool
+	// Match steps by event name instead of position, so steps inserted or
+	// removed in candidate script do not shift comparison. Steps without
+	// match or without event name are skipped
+	ByEventName bool
+}
+
+// MetricDelta is difference of metric between baseline and candidate
+type MetricDelta struct {
+	Metric    string
+	Baseline  float64
+	Candidate float64
+	// Candidate - Baseline, positive means candidate is slower (or bigger)
+	Delta float64
+	// Delta relative to Baseline (0.1 is +10%), 0 if Baseline is 0
+	Relative float64
+}
+
+// RequestChange is a request, that is present in both results, but differs
+type RequestChange struct {
+	URL       string
+	Baseline  Request
+	Candidate Request
+	// Difference of BytesIn
+	BytesDelta int
+}
+
+// RequestDiff is difference between requests of baseline and candidate,
+// requests are matched by full URL
+type RequestDiff struct {
+	Added   []Request
+	Removed []Request
+	Changed []RequestChange
+	// Difference of total BytesIn of all requests
+	BytesDelta int
+}
+
+// ViewComparison is comparison of first or repeat views of one step
+type ViewComparison struct {
+	// Deltas between median runs
+	Metrics []MetricDelta
+	// Deltas between runs with the same number, if CompareOptions.AllRuns is set
+	Runs map[string][]MetricDelta
+	// Diff of requests of median runs, nil if results have no request data
+	Requests *RequestDiff
+}
+
+// StepComparison is comparison of one step
+type StepComparison struct {
+	// 0-based index of step
+	Step int
+	// 0-based index of step in candidate, differs from Step only if
+	// steps were matched by event name
+	CandidateStep int
+	EventName     string
+
+	FirstView *ViewComparison
+	// nil if any of results has no successful repeat views
+	RepeatView *ViewComparison
+
+	// Why step could not be compared, like when every run of step failed.
+	// Views are nil then
+	Err error
+}
+
+// Comparison is result of comparison of two test results
+type Comparison struct {
+	BaselineID  string
+	CandidateID string
+	Steps       []StepComparison
+}
+
+// Compare will compare candidate result with baseline, step by step. Step, that
+// can't be compared, has its Err set, error is returned only if no step can be compared
+func Compare(baseline, candidate *ResultData, options CompareOptions) (*Comparison, error) {
+	if baseline == nil || candidate == nil {
+		return nil, errors.New("both baseline and candidate are required")
+	}
+	if len(options.Metrics) == 0 {
+		options.Metrics = DefaultCompareMetrics
+	}
+
+	comparison := &Comparison{BaselineID: baseline.ID, CandidateID: candidate.ID}
+	names := baseline.StepNames()
+	var compared int
+	var firstErr error
+	for step := range names {
+		candidateStep := step
+		if options.ByEventName {
+			if names[step] == "" {
+				continue
+			}
+			var err error
+			if candidateStep, err = candidate.StepIndex(names[step]); err != nil {
+				continue
+			}
+		} else if step >= candidate.numSteps() {
+			break
+		}
+
+		stepComparison, err := compareStep(baseline, candidate, step, candidateStep, options)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			stepComparison = &StepComparison{Step: step, CandidateStep: candidateStep, EventName: names[step], Err: err}
+		} else {
+			compared++
+		}
+		comparison.Steps = append(comparison.Steps, *stepComparison)
+	}
+	if compared == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, errors.New("no steps to compare")
+	}
+	return comparison, nil
+}
+
+func compareStep(baseline, candidate *ResultData, step, candidateStep int, options CompareOptions) (*StepComparison, error) {
+	baselineRun, err := baseline.GetMedianRun(step, options.MedianMetric)
+	if err != nil {
+		return nil, err
+	}
+	candidateRun, err := candidate.GetMedianRun(candidateStep, options.MedianMetric)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &StepComparison{
+		Step:          step,
+		CandidateStep: candidateStep,
+		EventName:     baselineRun.FirstView.Steps[step].EventName,
+		FirstView: compareView(baseline, candidate, &baselineRun.FirstView.Steps[step],
+			&candidateRun.FirstView.Steps[candidateStep], step, candidateStep, false, options),
+	}
+	if step < len(baselineRun.RepeatView.Steps) && candidateStep < len(candidateRun.RepeatView.Steps) {
+		comparison.RepeatView = compareView(baseline, candidate, &baselineRun.RepeatView.Steps[step],
+			&candidateRun.RepeatView.Steps[candidateStep], step, candidateStep, true, options)
+	}
+	return comparison, nil
+}
+
+func compareView(baseline, candidate *ResultData, baselineStep, candidateStep *TestStep,
+	step, candidateIdx int, cached bool, options CompareOptions) *ViewComparison {
+
+	comparison := &ViewComparison{
+		Metrics: compareMetrics(baselineStep, candidateStep, options.Metrics),
+	}
+	if baselineStep.Requests != nil && candidateStep.Requests != nil {
+		comparison.Requests = DiffRequests(baselineStep.Requests, candidateStep.Requests)
+	}
+
+	if options.AllRuns {
+		comparison.Runs = make(map[string][]MetricDelta)
+		for key, baselineRun := range baseline.Runs {
+			candidateRun, ok := candidate.Runs[key]
+			if !ok {
+				continue
+			}
+			baselineView, candidateView := baselineRun.FirstView, candidateRun.FirstView
+			if cached {
+				baselineView, candidateView = baselineRun.RepeatView, candidateRun.RepeatView
+			}
+			if step >= len(baselineView.Steps) || candidateIdx >= len(candidateView.Steps) {
+				continue
+			}
+			if !baselineView.Steps[step].Successful() || !candidateView.Steps[candidateIdx].Successful() {
+				continue
+			}
+			comparison.Runs[key] = compareMetrics(&baselineView.Steps[step], &candidateView.Steps[candidateIdx], options.Metrics)
+		}
+	}
+	return comparison
+}
+
+func compareMetrics(baseline, candidate *TestStep, metrics []string) []MetricDelta {
+	var deltas []MetricDelta
+	for _, metric := range metrics {
+		baselineValue, ok := baseline.Metric(metric)
+		if !ok {
+			continue
+		}
+		candidateValue, ok := candidate.Metric(metric)
+		if !ok {
+			continue
+		}
+		deltas = append(deltas, NewMetricDelta(metric, baselineValue, candidateValue))
+	}
+	return deltas
+}
+
+// NewMetricDelta will calculate delta between baseline and candidate values
+func NewMetricDelta(metric string, baseline, candidate float64) MetricDelta {
+	delta := MetricDelta{
+		Metric:    metric,
+		Baseline:  baseline,
+		Candidate: candidate,
+		Delta:     candidate - baseline,
+	}
+	if baseline != 0 {
+		delta.Relative = delta.Delta / baseline
+	}
+	return delta
+}
+
+// DiffRequests will find added, removed and changed (by size or response code)
+// requests. Requests to the same URL are matched in order of appearance
+func DiffRequests(baseline, candidate []Request) *RequestDiff {
+	diff := &RequestDiff{}
+
+	pending := make(map[string][]Request)
+	counts := make(map[string]int)
+	for _, request := range baseline {
+		pending[request.FullURL] = append(pending[request.FullURL], request)
+		counts[request.FullURL]++
+		diff.BytesDelta -= request.BytesIn
+	}
+
+	for _, request := range candidate {
+		diff.BytesDelta += request.BytesIn
+		matches := pending[request.FullURL]
+		if len(matches) == 0 {
+			diff.Added = append(diff.Added, request)
+			continue
+		}
+		match := matches[0]
+		pending[request.FullURL] = matches[1:]
+		if match.BytesIn != request.BytesIn || match.ResponseCode != request.ResponseCode {
+			diff.Changed = append(diff.Changed, RequestChange{
+				URL:        request.FullURL,
+				Baseline:   match,
+				Candidate:  request,
+				BytesDelta: request.BytesIn - match.BytesIn,
+			})
+		}
+	}
+
+	// Unmatched requests are the last ones among requests with the same URL
+	seen := make(map[string]int)
+	for _, request := range baseline {
+		seen[request.FullURL]++
+		matched := counts[request.FullURL] - len(pending[request.FullURL])
+		if seen[request.FullURL] > matched {
+			diff.Removed = append(diff.Removed, request)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,104 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	baseline := loadResult(t, "TestResultPlrAsNumber.json")
+	candidate := loadResult(t, "TestResultPlrAsNumber.json")
+	candidate.ID = "candidate"
+	for key := range candidate.Runs {
+		setStep(candidate, key, false, func(step *TestStep) {
+			step.Metrics["loadTime"] *= 1.1
+			step.Metrics["SpeedIndex"] = 0
+		})
+	}
+
+	comparison, err := Compare(baseline, candidate, CompareOptions{
+		Metrics: []string{"loadTime", "SpeedIndex", "missing"},
+		AllRuns: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "candidate", comparison.CandidateID)
+	assert.Len(t, comparison.Steps, 1)
+
+	firstView := comparison.Steps[0].FirstView
+	assert.Len(t, firstView.Metrics, 2)
+	assert.Equal(t, "loadTime", firstView.Metrics[0].Metric)
+	assert.Equal(t, 42755.0, firstView.Metrics[0].Baseline)
+	assert.InDelta(t, 4275.5, firstView.Metrics[0].Delta, 0.01)
+	assert.InDelta(t, 0.1, firstView.Metrics[0].Relative, 0.0001)
+	assert.Equal(t, -1.0, firstView.Metrics[1].Relative)
+	assert.Len(t, firstView.Runs, 5)
+	assert.InDelta(t, 0.1, firstView.Runs["1"][0].Relative, 0.0001)
+	assert.Nil(t, firstView.Requests)
+
+	repeatView := comparison.Steps[0].RepeatView
+	assert.Equal(t, 0.0, repeatView.Metrics[0].Delta)
+
+	_, err = Compare(baseline, nil, CompareOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestCompareSkipsSteps(t *testing.T) {
+	baseline := loadResult(t, "TestResultPlrAsNumber.json")
+	candidate := loadResult(t, "TestResultPlrAsNumber.json")
+	insertStep(baseline, "")
+	insertStep(candidate, "")
+
+	// Steps without event name can't be matched by it
+	comparison, err := Compare(baseline, candidate, CompareOptions{Metrics: []string{"loadTime"}, ByEventName: true})
+	assert.Nil(t, err)
+	assert.Len(t, comparison.Steps, 1)
+	assert.Equal(t, "Step 1", comparison.Steps[0].EventName)
+
+	// Step, that failed in every run, does not prevent comparison of others
+	for key, run := range baseline.Runs {
+		run.FirstView.Steps[0].Result = 404
+		run.RepeatView.Steps[0].Result = 404
+		baseline.Runs[key] = run
+	}
+	comparison, err = Compare(baseline, candidate, CompareOptions{Metrics: []string{"loadTime"}})
+	assert.Nil(t, err)
+	assert.Len(t, comparison.Steps, 2)
+	assert.NotNil(t, comparison.Steps[0].Err)
+	assert.Nil(t, comparison.Steps[0].FirstView)
+	assert.Nil(t, comparison.Steps[1].Err)
+	assert.Len(t, comparison.Steps[1].FirstView.Metrics, 1)
+
+	// Nothing to compare at all
+	for key, run := range baseline.Runs {
+		run.FirstView.Steps[1].Result = 404
+		run.RepeatView.Steps[1].Result = 404
+		baseline.Runs[key] = run
+	}
+	_, err = Compare(baseline, candidate, CompareOptions{Metrics: []string{"loadTime"}})
+	assert.NotNil(t, err)
+}
+
+func TestDiffRequests(t *testing.T) {
+	baseline := []Request{
+		{Number: 1, FullURL: "https://example.com/", BytesIn: 1000, ResponseCode: 200},
+		{Number: 2, FullURL: "https://example.com/app.js", BytesIn: 500, ResponseCode: 200},
+		{Number: 3, FullURL: "https://example.com/pixel.gif", BytesIn: 50, ResponseCode: 200},
+		{Number: 4, FullURL: "https://example.com/pixel.gif", BytesIn: 50, ResponseCode: 200},
+	}
+	candidate := []Request{
+		{Number: 1, FullURL: "https://example.com/", BytesIn: 1000, ResponseCode: 200},
+		{Number: 2, FullURL: "https://example.com/app.js", BytesIn: 800, ResponseCode: 200},
+		{Number: 3, FullURL: "https://example.com/pixel.gif", BytesIn: 50, ResponseCode: 200},
+		{Number: 4, FullURL: "https://example.com/font.woff2", BytesIn: 200, ResponseCode: 200},
+	}
+
+	diff := DiffRequests(baseline, candidate)
+	assert.Len(t, diff.Added, 1)
+	assert.Equal(t, "https://example.com/font.woff2", diff.Added[0].FullURL)
+	assert.Len(t, diff.Removed, 1)
+	assert.Equal(t, 4, diff.Removed[0].Number)
+	assert.Len(t, diff.Changed, 1)
+	assert.Equal(t, 300, diff.Changed[0].BytesDelta)
+	assert.Equal(t, 450, diff.BytesDelta)
+}
@@ -0,0 +1,47 @@
+package webpagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextCancellation(t *testing.T) {
+	var polls int32
+	wpt := newTestClient(t, testRoutes{
+		// Responds only after client gives up on request
+		"/getLocations.php": func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		},
+		"/testStatus.php": func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&polls, 1)
+			fmt.Fprint(w, `{"statusCode": 101, "data": {"statusCode": 101}}`)
+		},
+	})
+
+	// In-flight request is aborted
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := wpt.GetLocationsContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(started) < 10*time.Second)
+
+	// Wait loop stops polling
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = wpt.WaitForTest(ctx, "161128_R3_2", WaitOptions{PollInterval: 10 * time.Millisecond})
+	assert.True(t, errors.Is(err, context.Canceled))
+	// Poll, that was in flight when context was cancelled, may still reach server
+	time.Sleep(20 * time.Millisecond)
+	count := atomic.LoadInt32(&polls)
+	assert.True(t, count > 0)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, count, atomic.LoadInt32(&polls))
+}
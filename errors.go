@@ -0,0 +1,136 @@
+package webpagetest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors, that can be matched with errors.Is against errors returned by client
+var (
+	// ErrTestNotFound means that server does not know about test with given ID
+	ErrTestNotFound = errors.New("test not found")
+	// ErrInvalidAPIKey means that API key is missing, invalid or is not allowed to do requested action
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrQueueFull means that server (or location) rejected test because its queue is full
+	ErrQueueFull = errors.New("test queue is full")
+	// ErrRateLimited means that server throttles our requests, they can be made again later
+	ErrRateLimited = errors.New("rate limited")
+	// ErrQuotaExceeded means that API key is out of test runs, retrying will not help
+	ErrQuotaExceeded = errors.New("test quota exceeded")
+	// ErrServer means that server failed to process request (5XX)
+	ErrServer = errors.New("server error")
+	// ErrCancelFailed means that test can't be cancelled, it may have already started or been cancelled
+	ErrCancelFailed = errors.New("test could not be cancelled")
+	// ErrWaitTimeout means that test was not completed in time given by WaitOptions.Timeout
+	ErrWaitTimeout = errors.New("timed out waiting for test")
+	// ErrStepNotFound means that result has no step with given event name
+	ErrStepNotFound = errors.New("step not found")
+	// ErrInvalidSettings means that TestSettings failed validation, see ValidationErrors for details
+	ErrInvalidSettings = errors.New("invalid test settings")
+)
+
+// APIError describes failed call to WebPageTest API.
+// HTTPStatus is status of HTTP response, StatusCode and StatusText are taken
+// from "statusCode"/"statusText" of WebPageTest response, if there was any
+type APIError struct {
+	Endpoint   string
+	TestID     string
+	HTTPStatus int
+	StatusCode int
+	StatusText string
+}
+
+// Error implements error interface
+func (e *APIError) Error() string {
+	var target string
+	if e.TestID != "" {
+		target = fmt.Sprintf(" (test %s)", e.TestID)
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("webpagetest: %s%s failed with status %d: %s",
+			e.Endpoint, target, e.StatusCode, e.StatusText)
+	}
+	return fmt.Sprintf("webpagetest: %s%s failed with HTTP status %d: %s",
+		e.Endpoint, target, e.HTTPStatus, e.StatusText)
+}
+
+// apiErrorMessages are "statusText" of WebPageTest responses, that tell what
+// went wrong when "statusCode" is just 400. Messages are lower-cased and
+// matched as prefix, so details added after them don't matter
+var apiErrorMessages = []struct {
+	prefix string
+	err    error
+}{
+	{"test not found", ErrTestNotFound},
+	{"invalid api key", ErrInvalidAPIKey},
+	{"the test request will exceed the remaining test balance", ErrQuotaExceeded},
+	{"the test queue is full", ErrQueueFull},
+}
+
+// Is allows to match APIError with sentinel errors like ErrTestNotFound.
+// HTTP status or WebPageTest "statusCode" is used if it is specific enough,
+// otherwise StatusText is compared with known WebPageTest messages
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrServer:
+		return e.HTTPStatus >= 500 || e.StatusCode >= 500
+	case ErrCancelFailed:
+		return e.Endpoint == "/cancelTest.php" &&
+			strings.Contains(strings.ToLower(e.StatusText), "could not be cancelled")
+	}
+	return e.kind() == target
+}
+
+// testEndpoints are endpoints, that respond with 404 only for unknown test
+var testEndpoints = map[string]bool{
+	"/testStatus.php": true,
+	"/jsonResult.php": true,
+}
+
+// kind returns sentinel error, that describes APIError, or nil if there is none
+func (e *APIError) kind() error {
+	for _, status := range []int{e.StatusCode, e.HTTPStatus} {
+		switch {
+		case status == http.StatusNotFound && testEndpoints[e.Endpoint]:
+			return ErrTestNotFound
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			return ErrInvalidAPIKey
+		case status == http.StatusTooManyRequests:
+			return ErrRateLimited
+		}
+	}
+
+	text := strings.ToLower(strings.TrimSpace(e.StatusText))
+	for _, message := range apiErrorMessages {
+		if strings.HasPrefix(text, message.prefix) {
+			return message.err
+		}
+	}
+	return nil
+}
+
+// RequestError describes failure to make a request to server or to read its
+// response, like connection errors or timeouts. Original error is available with errors.Unwrap
+type RequestError struct {
+	Endpoint string
+	Err      error
+}
+
+// Error implements error interface
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("webpagetest: request to %s failed: %v", e.Endpoint, e.Err)
+}
+
+// Unwrap returns original error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// isTransient tells if request failed for reason, that may go away on its own,
+// like network or server error, so it is worth to try again later
+func isTransient(err error) bool {
+	var requestErr *RequestError
+	return errors.As(err, &requestErr) || errors.Is(err, ErrServer) || errors.Is(err, ErrRateLimited)
+}
@@ -0,0 +1,88 @@
+package webpagetest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrors(t *testing.T) {
+	server := newTestServer(t, testRoutes{
+		"/testStatus.php": respond(`{"statusCode": 400, "statusText": "Test not found"}`),
+		"/runtest.php":    respond(`{"statusCode": 400, "statusText": "Invalid API Key"}`),
+		"/cancelTest.php": respond(`<h3>Sorry, the test could not be cancelled.  It may have already started or been cancelled</h3><form>`),
+		"/getLocations.php": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	})
+
+	wpt, err := NewClient(server.URL)
+	assert.Nil(t, err)
+
+	_, err = wpt.GetTestStatus("161128_R3_2")
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "161128_R3_2", apiErr.TestID)
+	assert.Equal(t, 400, apiErr.StatusCode)
+	assert.True(t, errors.Is(err, ErrTestNotFound))
+	assert.False(t, errors.Is(err, ErrInvalidAPIKey))
+
+	_, err = wpt.RunTest(TestSettings{URL: "https://example.com"})
+	assert.True(t, errors.Is(err, ErrInvalidAPIKey))
+
+	err = wpt.CancelTest("161128_R3_2")
+	assert.True(t, errors.Is(err, ErrCancelFailed))
+
+	_, err = wpt.GetLocations()
+	assert.True(t, errors.Is(err, ErrServer))
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.HTTPStatus)
+
+	server.Close()
+	_, err = wpt.GetTesters()
+	var reqErr *RequestError
+	assert.True(t, errors.As(err, &reqErr))
+	assert.Equal(t, "/getTesters.php", reqErr.Endpoint)
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		err    APIError
+		target error
+		want   bool
+	}{
+		{APIError{Endpoint: "/testStatus.php", StatusCode: 404}, ErrTestNotFound, true},
+		{APIError{Endpoint: "/jsonResult.php", HTTPStatus: 404}, ErrTestNotFound, true},
+		{APIError{HTTPStatus: 429}, ErrRateLimited, true},
+		{APIError{StatusCode: 400, StatusText: "Test not found"}, ErrTestNotFound, true},
+		{APIError{StatusCode: 400, StatusText: "Invalid API Key"}, ErrInvalidAPIKey, true},
+		{APIError{StatusCode: 400, StatusText: "The test request will exceed the remaining test balance for the given API key"}, ErrQuotaExceeded, true},
+		{APIError{StatusCode: 400, StatusText: "The test request will exceed the remaining test balance for the given API key"}, ErrRateLimited, false},
+		{APIError{HTTPStatus: 503}, ErrServer, true},
+
+		// Messages that only mention the same words
+		{APIError{StatusCode: 400, StatusText: "Script exceeds the step limit"}, ErrRateLimited, false},
+		{APIError{StatusCode: 400, StatusText: "Invalid queue name"}, ErrQueueFull, false},
+		{APIError{StatusCode: 400, StatusText: "Location not found"}, ErrTestNotFound, false},
+		{APIError{StatusCode: 400, StatusText: "Missing API key header"}, ErrInvalidAPIKey, false},
+		// 404 of endpoints, that are not about one test
+		{APIError{Endpoint: "/getLocations.php", HTTPStatus: 404}, ErrTestNotFound, false},
+		{APIError{Endpoint: "/export.php", TestID: "161128_R3_2", HTTPStatus: 404}, ErrTestNotFound, false},
+		{APIError{Endpoint: "/cancelTest.php", TestID: "161128_R3_2", HTTPStatus: 404}, ErrTestNotFound, false},
+		// Status wins over message
+		{APIError{StatusCode: 403, StatusText: "Test not found"}, ErrTestNotFound, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, errors.Is(&test.err, test.target), "%+v", test.err)
+	}
+}
+
+func TestQuotaExceededIsNotTransient(t *testing.T) {
+	err := &APIError{Endpoint: "/runtest.php", StatusCode: 400,
+		StatusText: "The test request will exceed the remaining test balance for the given API key"}
+	assert.False(t, isTransient(err))
+	assert.True(t, isTransient(&APIError{Endpoint: "/runtest.php", HTTPStatus: 429}))
+}
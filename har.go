@@ -0,0 +1,369 @@
+package webpagetest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// export.php
+// http://www.softwareishard.com/blog/har-12-spec/
+
+// HAR is HTTP Archive 1.2 document, as exported by WebPageTest
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is root of exported data
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Browser *HARCreator `json:"browser,omitempty"`
+	Pages   []HARPage   `json:"pages,omitempty"`
+	Entries []HAREntry  `json:"entries"`
+	Comment string      `json:"comment,omitempty"`
+}
+
+// HARCreator describes application that created log or browser that was used
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// HARPage is one exported page, WebPageTest exports every run and view as a page
+type HARPage struct {
+	StartedDateTime string         `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     HARPageTimings `json:"pageTimings"`
+	Comment         string         `json:"comment,omitempty"`
+
+	// WebPageTest specific "_"-prefixed fields, like "_loadTime" or "_SpeedIndex"
+	Extensions HARExtensions `json:"-"`
+}
+
+// HARPageTimings are timings of page load events in ms, -1 if not available
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+	Comment       string  `json:"comment,omitempty"`
+
+	// WebPageTest specific "_"-prefixed fields, like "_startRender"
+	Extensions HARExtensions `json:"-"`
+}
+
+// HAREntry is one exported HTTP request
+type HAREntry struct {
+	Pageref         string      `json:"pageref,omitempty"`
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           HARCache    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+
+	// WebPageTest specific "_"-prefixed fields, like "_priority" or "_initiator"
+	Extensions HARExtensions `json:"-"`
+}
+
+// HARRequest is request part of HAREntry
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+// HARResponse is response part of HAREntry
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+// HARCookie is cookie sent with request or set by response
+type HARCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// HARNameValue is a header or query string parameter
+type HARNameValue struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// HARPostData is posted data of request
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []HARPostParam `json:"params,omitempty"`
+	Text     string         `json:"text"`
+	Comment  string         `json:"comment,omitempty"`
+}
+
+// HARPostParam is posted parameter
+type HARPostParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// HARContent is content of response, Text is set only when bodies were exported
+type HARContent struct {
+	Size        int    `json:"size"`
+	Compression int    `json:"compression,omitempty"`
+	MimeType    string `json:"mimeType"`
+	Text        string `json:"text,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// HARCache is info about cache usage
+type HARCache struct {
+	BeforeRequest *HARCacheEntry `json:"beforeRequest,omitempty"`
+	AfterRequest  *HARCacheEntry `json:"afterRequest,omitempty"`
+	Comment       string         `json:"comment,omitempty"`
+}
+
+// HARCacheEntry is state of cache entry
+type HARCacheEntry struct {
+	Expires    string `json:"expires,omitempty"`
+	LastAccess string `json:"lastAccess"`
+	ETag       string `json:"eTag"`
+	HitCount   int    `json:"hitCount"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// HARTimings are phases of request in ms, -1 if phase is not applicable
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+	Comment string  `json:"comment,omitempty"`
+
+	// WebPageTest specific "_"-prefixed fields
+	Extensions HARExtensions `json:"-"`
+}
+
+// HARExtensions are custom "_"-prefixed fields, keys are stored without "_"
+type HARExtensions map[string]json.RawMessage
+
+// String returns extension as a string, numbers are formatted as is
+func (e HARExtensions) String(name string) (string, bool) {
+	raw, ok := e[name]
+	if !ok {
+		return "", false
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return strings.Trim(string(raw), `"`), true
+	}
+	return value, true
+}
+
+// Float returns numeric extension, numbers in strings (like "123") are accepted
+func (e HARExtensions) Float(name string) (float64, bool) {
+	value, ok := e.String(name)
+	if !ok {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping "_"-prefixed fields in Extensions
+func (p *HARPage) UnmarshalJSON(b []byte) error {
+	type page HARPage
+	var tmp page
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	*p = HARPage(tmp)
+
+	var err error
+	p.Extensions, err = parseHARExtensions(b)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, putting Extensions back with "_" prefix
+func (p HARPage) MarshalJSON() ([]byte, error) {
+	type page HARPage
+	return marshalHARExtensions(page(p), p.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping "_"-prefixed fields in Extensions
+func (e *HAREntry) UnmarshalJSON(b []byte) error {
+	type entry HAREntry
+	var tmp entry
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	*e = HAREntry(tmp)
+
+	var err error
+	e.Extensions, err = parseHARExtensions(b)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, putting Extensions back with "_" prefix
+func (e HAREntry) MarshalJSON() ([]byte, error) {
+	type entry HAREntry
+	return marshalHARExtensions(entry(e), e.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping "_"-prefixed fields in Extensions
+func (t *HARPageTimings) UnmarshalJSON(b []byte) error {
+	type timings HARPageTimings
+	var tmp timings
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	*t = HARPageTimings(tmp)
+
+	var err error
+	t.Extensions, err = parseHARExtensions(b)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, putting Extensions back with "_" prefix
+func (t HARPageTimings) MarshalJSON() ([]byte, error) {
+	type timings HARPageTimings
+	return marshalHARExtensions(timings(t), t.Extensions)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping "_"-prefixed fields in Extensions
+func (t *HARTimings) UnmarshalJSON(b []byte) error {
+	type timings HARTimings
+	var tmp timings
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	*t = HARTimings(tmp)
+
+	var err error
+	t.Extensions, err = parseHARExtensions(b)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, putting Extensions back with "_" prefix
+func (t HARTimings) MarshalJSON() ([]byte, error) {
+	type timings HARTimings
+	return marshalHARExtensions(timings(t), t.Extensions)
+}
+
+func parseHARExtensions(b []byte) (HARExtensions, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	extensions := make(HARExtensions)
+	for key, value := range fields {
+		if strings.HasPrefix(key, "_") {
+			extensions[key[1:]] = value
+		}
+	}
+	return extensions, nil
+}
+
+func marshalHARExtensions(v interface{}, extensions HARExtensions) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return b, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range extensions {
+		fields["_"+key] = value
+	}
+	return json.Marshal(fields)
+}
+
+// HAROptions are options for HAR export
+type HAROptions struct {
+	// Include response bodies (if they were saved for test)
+	Bodies bool
+	// Export only given run, 0 means all runs
+	Run int
+	// Export only repeat view of Run
+	Cached bool
+}
+
+// GetHAR will retrieve HTTP Archive of test by given testID
+func (w *WebPageTest) GetHAR(testID string, options HAROptions) (*HAR, error) {
+	return w.GetHARContext(context.Background(), testID, options)
+}
+
+// GetHARContext is like GetHAR but uses ctx for the underlying request
+func (w *WebPageTest) GetHARContext(ctx context.Context, testID string, options HAROptions) (*HAR, error) {
+	query := url.Values{}
+	query.Add("test", testID)
+	if options.Bodies {
+		query.Add("bodies", "1")
+	}
+	if options.Run > 0 {
+		query.Add("run", strconv.Itoa(options.Run))
+		if options.Cached {
+			query.Add("cached", "1")
+		}
+	}
+
+	body, err := w.query(ctx, "/export.php", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var har HAR
+	if err = json.Unmarshal(body, &har); err != nil {
+		return nil, err
+	}
+	if har.Log.Version == "" {
+		return nil, &APIError{
+			Endpoint:   "/export.php",
+			TestID:     testID,
+			HTTPStatus: http.StatusOK,
+			StatusText: "empty HAR log",
+		}
+	}
+
+	return &har, nil
+}
@@ -0,0 +1,88 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHAR = `{"log": {
+	"version": "1.1",
+	"creator": {"name": "WebPagetest", "version": "2.19"},
+	"browser": {"name": "Google Chrome", "version": "67.0.3396.62"},
+	"pages": [{
+		"startedDateTime": "2018-06-03T19:58:56.950+00:00",
+		"title": "Run 1, First View for https://dertour.de",
+		"id": "page_1_0_1",
+		"pageTimings": {"onLoad": 3735, "onContentLoad": -1, "_startRender": 3100},
+		"_URL": "https://dertour.de",
+		"_loadTime": 3735,
+		"_SpeedIndex": "3215"
+	}],
+	"entries": [{
+		"pageref": "page_1_0_1",
+		"startedDateTime": "2018-06-03T19:58:56.950+00:00",
+		"time": 1015,
+		"request": {"method": "GET", "url": "https://dertour.de/", "httpVersion": "HTTP/1.1",
+			"headers": [{"name": "Host", "value": "dertour.de"}], "cookies": [], "queryString": [],
+			"headersSize": -1, "bodySize": -1},
+		"response": {"status": 301, "statusText": "", "httpVersion": "HTTP/1.1", "headers": [], "cookies": [],
+			"content": {"size": 0, "mimeType": "text/html"}, "redirectURL": "https://www.dertour.de/",
+			"headersSize": -1, "bodySize": 0},
+		"cache": {},
+		"timings": {"blocked": -1, "dns": 12, "connect": 200, "ssl": 131, "send": 0, "wait": 300, "receive": 0, "_queued": 5},
+		"_priority": "VeryHigh",
+		"_ttfb_ms": 300
+	}]
+}}`
+
+func TestGetHAR(t *testing.T) {
+	wpt := newTestClient(t, testRoutes{
+		"/export.php": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("test") != "180603_X1_1" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			assert.Equal(t, "1", r.URL.Query().Get("bodies"))
+			w.Write([]byte(testHAR))
+		},
+	})
+
+	har, err := wpt.GetHAR("180603_X1_1", HAROptions{Bodies: true})
+	assert.Nil(t, err)
+	assert.Len(t, har.Log.Pages, 1)
+	assert.Len(t, har.Log.Entries, 1)
+
+	page := har.Log.Pages[0]
+	assert.Equal(t, 3735.0, page.PageTimings.OnLoad)
+	startRender, ok := page.PageTimings.Extensions.Float("startRender")
+	assert.True(t, ok)
+	assert.Equal(t, 3100.0, startRender)
+	url, _ := page.Extensions.String("URL")
+	assert.Equal(t, "https://dertour.de", url)
+	speedIndex, ok := page.Extensions.Float("SpeedIndex")
+	assert.True(t, ok)
+	assert.Equal(t, 3215.0, speedIndex)
+
+	entry := har.Log.Entries[0]
+	assert.Equal(t, 301, entry.Response.Status)
+	assert.Equal(t, 131.0, entry.Timings.SSL)
+	queued, _ := entry.Timings.Extensions.Float("queued")
+	assert.Equal(t, 5.0, queued)
+	priority, _ := entry.Extensions.String("priority")
+	assert.Equal(t, "VeryHigh", priority)
+
+	// Extensions survive round trip
+	b, err := json.Marshal(har)
+	assert.Nil(t, err)
+	var decoded HAR
+	assert.Nil(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, har.Log.Entries[0].Extensions, decoded.Log.Entries[0].Extensions)
+	assert.Equal(t, har.Log.Pages[0].PageTimings, decoded.Log.Pages[0].PageTimings)
+	assert.Equal(t, har.Log.Entries[0].Timings, decoded.Log.Entries[0].Timings)
+
+	_, err = wpt.GetHAR("unknown", HAROptions{})
+	assert.NotNil(t, err)
+}
@@ -1,8 +1,9 @@
 package webpagetest
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 )
@@ -77,7 +78,12 @@ type Locations map[string][]Location
 // GetLocations will retrieve all available locations from server
 // You can request a list of locations as well as the number of pending tests for each
 func (w *WebPageTest) GetLocations() (*Locations, error) {
-	body, err := w.query("/getLocations.php", url.Values{"f": []string{"json"}})
+	return w.GetLocationsContext(context.Background())
+}
+
+// GetLocationsContext is like GetLocations but uses ctx for the underlying request
+func (w *WebPageTest) GetLocationsContext(ctx context.Context) (*Locations, error) {
+	body, err := w.query(ctx, "/getLocations.php", url.Values{"f": []string{"json"}})
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +94,12 @@ func (w *WebPageTest) GetLocations() (*Locations, error) {
 	}
 
 	if locations.StatusCode != 200 {
-		return nil, fmt.Errorf("Status != 200: %v", locations.StatusText)
+		return nil, &APIError{
+			Endpoint:   "/getLocations.php",
+			HTTPStatus: http.StatusOK,
+			StatusCode: locations.StatusCode,
+			StatusText: locations.StatusText,
+		}
 	}
 
 	result := make(Locations, 0)
@@ -0,0 +1,85 @@
+package webpagetest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Successful will return true if step has no errors. Like WebPageTest itself,
+// result 99999 (content errors, like 404 of some resource) counts as success
+func (ts *TestStep) Successful() bool {
+	return ts.Result == 0 || ts.Result == 99999
+}
+
+// runValue is value of metric in particular run
+type runValue struct {
+	run   int
+	key   string
+	value float64
+}
+
+// viewValues will return values of metric of successful runs for given step
+// and view, sorted by value and then by run number
+func (rd *ResultData) viewValues(step int, metric string, cached bool) []runValue {
+	var values []runValue
+	for key, run := range rd.Runs {
+		view := run.FirstView
+		if cached {
+			view = run.RepeatView
+		}
+		if step < 0 || step >= len(view.Steps) || !view.Steps[step].Successful() {
+			continue
+		}
+		value, ok := view.Steps[step].Metric(metric)
+		if !ok {
+			continue
+		}
+		number, _ := strconv.Atoi(key)
+		values = append(values, runValue{run: number, key: key, value: value})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].value != values[j].value {
+			return values[i].value < values[j].value
+		}
+		return values[i].run < values[j].run
+	})
+	return values
+}
+
+// medianValue picks median like WebPageTest does: middle one for odd number
+// of runs and lower (faster) of two middle ones for even number of runs
+func medianValue(values []runValue) runValue {
+	return values[(len(values)-1)/2]
+}
+
+// GetMedianRun will calculate and return median run by given metric and step
+// Step is 0-based. Metric is any metric supported by TestStep.Metric, like
+// TestSettings.MedianMetric. Empty metric means metric the test was started
+// with (TestInfo.MedianMetric) or "loadTime" if it was not set, as in WebPageTest.
+// Median of first and repeat views are picked independently, among
+// successful runs only, so they can come from different runs. RepeatView is
+// empty if there are no successful repeat views (like in first view only tests)
+func (rd *ResultData) GetMedianRun(step int, metric string) (*TestRun, error) {
+	if metric == "" {
+		metric = rd.TestInfo.MedianMetric
+	}
+	if metric == "" {
+		metric = "loadTime"
+	}
+
+	firstView := rd.viewValues(step, metric, false)
+	if len(firstView) == 0 {
+		return nil, fmt.Errorf("no successful runs with metric %q for step %d", metric, step)
+	}
+
+	var testRun TestRun
+	testRun.FirstView = rd.Runs[medianValue(firstView).key].FirstView
+
+	if repeatView := rd.viewValues(step, metric, true); len(repeatView) > 0 {
+		testRun.RepeatView = rd.Runs[medianValue(repeatView).key].RepeatView
+	}
+
+	return &testRun, nil
+}
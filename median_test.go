@@ -0,0 +1,128 @@
+package webpagetest
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loadResult(t *testing.T, name string) *ResultData {
+	response, err := ioutil.ReadFile("./testdata/" + name)
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+	return result
+}
+
+// setStep changes first step of given run and view
+func setStep(result *ResultData, run string, cached bool, change func(step *TestStep)) {
+	testRun := result.Runs[run]
+	view := &testRun.FirstView
+	if cached {
+		view = &testRun.RepeatView
+	}
+	change(&view.Steps[0])
+	result.Runs[run] = testRun
+}
+
+func TestGetMedianRun(t *testing.T) {
+	// Load times of first views are 43226, 42602, 42743, 42755, 43102
+	// and 12089, 10047, 9527, 10264, 10455 of repeat views
+	tests := []struct {
+		name       string
+		metric     string
+		change     func(result *ResultData)
+		firstView  float64
+		repeatView float64
+		err        bool
+	}{
+		{
+			// Same as in "median" of fixture, calculated by WebPageTest
+			name:      "odd number of runs",
+			firstView: 4, repeatView: 4,
+		},
+		{
+			name:   "even number of runs picks faster one",
+			change: func(result *ResultData) { delete(result.Runs, "5") },
+			// 42602, 42743, 42755, 43226 and 9527, 10047, 10264, 12089
+			firstView: 3, repeatView: 2,
+		},
+		{
+			name:   "medians of views are independent",
+			metric: "SpeedIndex",
+			// 7548, 7984, 8055, 8221, 8489 and 2865, 2865, 3041, 3294, 3381
+			firstView: 2, repeatView: 3,
+		},
+		{
+			name: "ties are ordered by run",
+			change: func(result *ResultData) {
+				for _, run := range []string{"2", "3", "5"} {
+					setStep(result, run, false, func(step *TestStep) { step.Metrics["loadTime"] = 42700 })
+				}
+			},
+			// 42700 (2), 42700 (3), 42700 (5), 42755 (4), 43226 (1)
+			firstView: 5, repeatView: 4,
+		},
+		{
+			name: "failed runs are skipped",
+			change: func(result *ResultData) {
+				setStep(result, "4", false, func(step *TestStep) { step.Result = 404 })
+				setStep(result, "1", true, func(step *TestStep) { step.Result = 99999 })
+			},
+			firstView: 3, repeatView: 4,
+		},
+		{
+			name: "first view only",
+			change: func(result *ResultData) {
+				result.FirstViewOnly = true
+				for key, run := range result.Runs {
+					run.RepeatView = TestView{}
+					result.Runs[key] = run
+				}
+			},
+			firstView: 4, repeatView: 0,
+		},
+		{
+			name: "all runs failed",
+			change: func(result *ResultData) {
+				for key := range result.Runs {
+					setStep(result, key, false, func(step *TestStep) { step.Result = 12999 })
+				}
+			},
+			err: true,
+		},
+		{
+			name:   "unknown metric",
+			metric: "missing",
+			err:    true,
+		},
+	}
+
+	for _, test := range tests {
+		result := loadResult(t, "TestResultPlrAsNumber.json")
+		if test.change != nil {
+			test.change(result)
+		}
+
+		run, err := result.GetMedianRun(0, test.metric)
+		if test.err {
+			assert.NotNil(t, err, test.name)
+			continue
+		}
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.firstView, run.FirstView.Run, test.name)
+		assert.Equal(t, test.repeatView, run.RepeatView.Run, test.name)
+	}
+}
+
+func TestGetMedianRunSingleRun(t *testing.T) {
+	result := loadResult(t, "TestResultPlrAsString.json")
+
+	run, err := result.GetMedianRun(0, "SpeedIndex")
+	assert.Nil(t, err)
+	assert.Equal(t, 3131, run.FirstView.Steps[0].SpeedIndex)
+
+	_, err = result.GetMedianRun(1, "SpeedIndex")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,80 @@
+package webpagetest
+
+import (
+	"strings"
+	"sync"
+)
+
+// MetricFunc computes metric of step, ok is false if step has no such metric
+type MetricFunc func(step *TestStep) (value float64, ok bool)
+
+var (
+	metricsMu sync.RWMutex
+	// Registered metrics by lower-cased name
+	metrics = map[string]MetricFunc{
+		"startrender":            metricAlias("render"),
+		"timetointeractive":      metricAlias("TTIMeasurementEnd"),
+		"largestcontentfulpaint": metricAlias("chromeUserTiming.LargestContentfulPaint"),
+		"cumulativelayoutshift":  metricAlias("chromeUserTiming.CumulativeLayoutShift"),
+		"totalblockingtime":      metricAlias("TotalBlockingTime"),
+	}
+)
+
+// RegisterMetric will make metric with given name available for TestStep.Metric
+// and GetMedianRun. Registered metrics take precedence over metrics
+// reported by WebPageTest. Names are case-insensitive, so metric replaces
+// one registered before with name, that differs only in case
+func RegisterMetric(name string, fn MetricFunc) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	metrics[strings.ToLower(name)] = fn
+}
+
+func metricAlias(name string) MetricFunc {
+	return func(step *TestStep) (float64, bool) {
+		value, ok := step.Metrics[name]
+		return value, ok
+	}
+}
+
+func registeredMetric(name string) (MetricFunc, bool) {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+	fn, ok := metrics[strings.ToLower(name)]
+	return fn, ok
+}
+
+// Metric will return value of metric by name. Name can be any numeric key of
+// WebPageTest json, like "firstContentfulPaint", "userTime.hero" or
+// "userTimingMeasure.api", name of custom metric or registered metric.
+// Lookup is case-insensitive, so "loadtime" and "loadTime" are the same
+func (ts *TestStep) Metric(name string) (float64, bool) {
+	if fn, ok := registeredMetric(name); ok {
+		if value, ok := fn(ts); ok {
+			return value, true
+		}
+	}
+
+	if value, ok := ts.Metrics[name]; ok {
+		return value, true
+	}
+	if value, ok := ts.CustomMetricFloat(name); ok {
+		return value, true
+	}
+	// Chrome reports some metrics only as user timings
+	if value, ok := ts.Metrics["chromeUserTiming."+name]; ok {
+		return value, true
+	}
+
+	for key, value := range ts.Metrics {
+		if strings.EqualFold(key, name) || strings.EqualFold(key, "chromeUserTiming."+name) {
+			return value, true
+		}
+	}
+	for key := range ts.CustomMetrics {
+		if strings.EqualFold(key, name) {
+			return ts.CustomMetricFloat(key)
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,77 @@
+package webpagetest
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepMetric(t *testing.T) {
+	response, err := ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+	step := result.Runs["1"].FirstView.Steps[0]
+
+	for name, expected := range map[string]float64{
+		"firstContentfulPaint":                 3100,
+		"loadtime":                             3735,
+		"startRender":                          3100,
+		"firstMeaningfulPaintCandidate":        2484,
+		"userTime.mark_adobe_target_show_body": 3005,
+		"Colordepth":                           24,
+	} {
+		value, ok := step.Metric(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, value, name)
+	}
+
+	_, ok := step.Metric("Resolution")
+	assert.False(t, ok)
+	_, ok = step.Metric("missing")
+	assert.False(t, ok)
+
+	RegisterMetric("renderToLoad", func(step *TestStep) (float64, bool) {
+		return float64(step.LoadTime - step.Render), true
+	})
+	t.Cleanup(func() {
+		metricsMu.Lock()
+		defer metricsMu.Unlock()
+		delete(metrics, "rendertoload")
+	})
+	value, ok := step.Metric("RenderToLoad")
+	assert.True(t, ok)
+	assert.Equal(t, 635.0, value)
+
+	// Name, that differs only in case, replaces metric
+	RegisterMetric("RENDERTOLOAD", func(step *TestStep) (float64, bool) {
+		return 1, true
+	})
+	for i := 0; i < 10; i++ {
+		value, _ = step.Metric("renderToLoad")
+		assert.Equal(t, 1.0, value)
+	}
+}
+
+func TestGetMedianRunByMetric(t *testing.T) {
+	response, err := ioutil.ReadFile("./testdata/TestResultPlrAsNumber.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+
+	for _, metric := range []string{"", "loadtime", "loadTime", "userTime.page content ready"} {
+		run, err := result.GetMedianRun(0, metric)
+		assert.Nil(t, err, metric)
+		assert.Equal(t, 4.0, run.FirstView.Run, metric)
+	}
+
+	_, err = result.GetMedianRun(0, "missing")
+	assert.NotNil(t, err)
+
+	// Median metric of test is used by default
+	result.TestInfo.MedianMetric = "SpeedIndex"
+	byDefault, err := result.GetMedianRun(0, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2.0, byDefault.FirstView.Run)
+}
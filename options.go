@@ -0,0 +1,48 @@
+package webpagetest
+
+import (
+	"net/http"
+)
+
+// Option is a functional option for configuring WebPageTest client in NewClient
+type Option func(*WebPageTest)
+
+// WithHTTPClient sets http.Client that will be used for all requests to server,
+// so you can set timeouts, proxies or custom TLS config for private instances
+func WithHTTPClient(client *http.Client) Option {
+	return func(w *WebPageTest) {
+		if client != nil {
+			w.client = client
+		}
+	}
+}
+
+// WithTransport sets http.RoundTripper for client's requests, e.g. to record or
+// stub responses in tests. It is applied on top of client set by WithHTTPClient
+func WithTransport(transport http.RoundTripper) Option {
+	return func(w *WebPageTest) {
+		w.transport = transport
+	}
+}
+
+// WithUserAgent sets User-Agent header for all requests
+func WithUserAgent(userAgent string) Option {
+	return func(w *WebPageTest) {
+		w.userAgent = userAgent
+	}
+}
+
+// WithAPIKey sets default API key, that will be passed as "k" parameter
+// to every call, unless it was given explicitly (like TestSettings.APIKey)
+func WithAPIKey(key string) Option {
+	return func(w *WebPageTest) {
+		w.apiKey = key
+	}
+}
+
+// WithHeader adds extra header to all requests, e.g. "X-WPT-API-KEY"
+func WithHeader(key, value string) Option {
+	return func(w *WebPageTest) {
+		w.headers.Add(key, value)
+	}
+}
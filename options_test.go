@@ -0,0 +1,49 @@
+package webpagetest
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientOptions(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]*http.Request)
+	record := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			mu.Lock()
+			requests[r.URL.Path] = r
+			mu.Unlock()
+			w.Write([]byte(body))
+		}
+	}
+	wpt := newTestClient(t, testRoutes{
+		"/runtest.php":    record(`{"statusCode": 200, "data": {"testId": "161128_R3_2"}}`),
+		"/cancelTest.php": record(`<h3 align="center">Test cancelled!</h3>`),
+	},
+		WithUserAgent("wpt-test/1.0"),
+		WithHeader("X-WPT-API-KEY", "secret"),
+		WithAPIKey("secret"),
+	)
+
+	_, err := wpt.RunTest(TestSettings{URL: "https://example.com"})
+	assert.Nil(t, err)
+	assert.Nil(t, wpt.CancelTest("161128_R3_2"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	for endpoint, method := range map[string]string{"/runtest.php": http.MethodPost, "/cancelTest.php": http.MethodGet} {
+		req := requests[endpoint]
+		if !assert.NotNil(t, req, endpoint) {
+			continue
+		}
+		assert.Equal(t, method, req.Method, endpoint)
+		assert.Equal(t, "wpt-test/1.0", req.Header.Get("User-Agent"), endpoint)
+		assert.Equal(t, "secret", req.Header.Get("X-WPT-API-KEY"), endpoint)
+		assert.Equal(t, "secret", req.Form.Get("k"), endpoint)
+	}
+
+}
@@ -0,0 +1,162 @@
+package webpagetest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// pingbackTTL is how long pingback for test, that is not registered yet, is
+// kept. Pingback can arrive before runtest.php response, if test is fast
+const pingbackTTL = time.Minute
+
+// PingbackResult is result of test, that was delivered by PingbackReceiver
+type PingbackResult struct {
+	TestID string
+	Result *ResultData
+	Err    error
+}
+
+// PingbackReceiver is http.Handler for pingbacks, that WebPageTest sends to
+// TestSettings.Pingback URL when test is completed (test ID is passed as "id" parameter).
+// Results of tests registered with Expect are fetched and delivered to Results channel.
+// Receiver can also be passed to WaitOptions, so waiting is finished as soon
+// as pingback arrives and polling is used only as a fallback
+type PingbackReceiver struct {
+	// Public URL of this handler, it will be used as TestSettings.Pingback
+	// by RunTestAndWaitWithOptions, if it is not set
+	URL string
+
+	client  *WebPageTest
+	results chan PingbackResult
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	expected map[string]bool
+	waiters  map[string]chan struct{}
+	// Pingbacks of tests, that were not registered yet, by time of arrival
+	early map[string]time.Time
+}
+
+// NewPingbackReceiver creates receiver that will fetch results with this client.
+// pingbackURL is URL, where receiver will be reachable for WebPageTest server
+func (w *WebPageTest) NewPingbackReceiver(pingbackURL string) *PingbackReceiver {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PingbackReceiver{
+		URL:      pingbackURL,
+		client:   w,
+		results:  make(chan PingbackResult),
+		ctx:      ctx,
+		cancel:   cancel,
+		expected: make(map[string]bool),
+		waiters:  make(map[string]chan struct{}),
+		early:    make(map[string]time.Time),
+	}
+}
+
+// Expect registers test, which result should be delivered to Results channel.
+// If pingback for test has already arrived, result is fetched right away
+func (p *PingbackReceiver) Expect(testID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.takeEarly(testID) {
+		go p.deliver(testID)
+		return
+	}
+	p.expected[testID] = true
+}
+
+// Results returns channel with results of expected tests. It must be read
+// until Close, results that nobody reads are dropped on Close
+func (p *PingbackReceiver) Results() <-chan PingbackResult {
+	return p.results
+}
+
+// Close stops fetching and delivering results, pending deliveries are dropped
+func (p *PingbackReceiver) Close() {
+	p.cancel()
+}
+
+// ServeHTTP implements http.Handler
+func (p *PingbackReceiver) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	testID := r.FormValue("id")
+	if testID == "" {
+		http.Error(rw, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	expected := p.expected[testID]
+	delete(p.expected, testID)
+	waiter, waiting := p.waiters[testID]
+	if !expected && !waiting {
+		// Test may be not registered yet, keep pingback for a while
+		p.pruneEarly(time.Now())
+		p.early[testID] = time.Now()
+	}
+	p.mu.Unlock()
+
+	if waiting {
+		// Waiter will fetch result itself, we only wake it up
+		select {
+		case waiter <- struct{}{}:
+		default:
+		}
+	}
+	if expected {
+		go p.deliver(testID)
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// deliver fetches result of test and sends it to Results, unless receiver is closed
+func (p *PingbackReceiver) deliver(testID string) {
+	result, err := p.client.GetTestResultContext(p.ctx, testID)
+	select {
+	case p.results <- PingbackResult{TestID: testID, Result: result, Err: err}:
+	case <-p.ctx.Done():
+	}
+}
+
+// wait returns channel, that will receive value when pingback for test arrives
+func (p *PingbackReceiver) wait(testID string) <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	waiter := make(chan struct{}, 1)
+	if p.takeEarly(testID) {
+		waiter <- struct{}{}
+	}
+	p.waiters[testID] = waiter
+	return waiter
+}
+
+// takeEarly removes early pingback of test, returns true if there was one.
+// Must be called with p.mu held
+func (p *PingbackReceiver) takeEarly(testID string) bool {
+	p.pruneEarly(time.Now())
+	_, ok := p.early[testID]
+	delete(p.early, testID)
+	return ok
+}
+
+// pruneEarly drops early pingbacks older than pingbackTTL. Must be called with p.mu held
+func (p *PingbackReceiver) pruneEarly(now time.Time) {
+	for testID, arrived := range p.early {
+		if now.Sub(arrived) > pingbackTTL {
+			delete(p.early, testID)
+		}
+	}
+}
+
+// forget removes waiter for test
+func (p *PingbackReceiver) forget(testID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.waiters, testID)
+}
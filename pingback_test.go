@@ -0,0 +1,117 @@
+package webpagetest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPingbackReceiver(t *testing.T) {
+	result, err := ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+
+	var complete int32
+	wpt := newTestClient(t, testRoutes{
+		"/runtest.php": respond(`{"statusCode": 200, "data": {"testId": "161128_R3_2"}}`),
+		"/testStatus.php": func(w http.ResponseWriter, r *http.Request) {
+			code := 100
+			if atomic.LoadInt32(&complete) == 1 {
+				code = 200
+			}
+			fmt.Fprintf(w, `{"statusCode": %d, "data": {"statusCode": %d}}`, code, code)
+		},
+		"/jsonResult.php": respond(string(result)),
+	})
+	receiver := wpt.NewPingbackReceiver("http://example.com/pingback")
+	defer receiver.Close()
+
+	rec := httptest.NewRecorder()
+	receiver.ServeHTTP(rec, httptest.NewRequest("GET", "/pingback", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	receiver.Expect("171113_2M_S")
+	rec = httptest.NewRecorder()
+	receiver.ServeHTTP(rec, httptest.NewRequest("GET", "/pingback?id=171113_2M_S", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	delivered := <-receiver.Results()
+	assert.Nil(t, delivered.Err)
+	assert.Equal(t, "171113_2M_S", delivered.TestID)
+	assert.Equal(t, "171113_2M_S", delivered.Result.ID)
+
+	// Pingback, that arrived before test was registered, is not lost
+	rec = httptest.NewRecorder()
+	receiver.ServeHTTP(rec, httptest.NewRequest("GET", "/pingback?id=fast", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	receiver.Expect("fast")
+	delivered = <-receiver.Results()
+	assert.Equal(t, "fast", delivered.TestID)
+
+	// Waiting should finish on pingback long before next poll
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreInt32(&complete, 1)
+		receiver.ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest("GET", "/pingback?id=161128_R3_2", nil))
+	}()
+	started := time.Now()
+	data, err := wpt.RunTestAndWaitWithOptions(context.Background(), TestSettings{URL: "https://example.com"},
+		WaitOptions{PollInterval: time.Minute, Pingback: receiver})
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+	assert.True(t, time.Since(started) < 10*time.Second)
+}
+
+func TestPingbackReceiverEarlyPingback(t *testing.T) {
+	var polls int32
+	wpt := newTestClient(t, testRoutes{
+		"/testStatus.php": func(w http.ResponseWriter, r *http.Request) {
+			// Test is completed only on second poll, that is made right after pingback
+			code := 100
+			if atomic.AddInt32(&polls, 1) > 1 {
+				code = 200
+			}
+			fmt.Fprintf(w, `{"statusCode": %d, "data": {"statusCode": %d}}`, code, code)
+		},
+		"/jsonResult.php": respond(`{"statusCode": 200, "data": {"id": "early"}}`),
+	})
+	receiver := wpt.NewPingbackReceiver("http://example.com/pingback")
+	defer receiver.Close()
+
+	receiver.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pingback?id=early", nil))
+	data, err := wpt.WaitForTest(context.Background(), "early", WaitOptions{
+		PollInterval: time.Minute,
+		Timeout:      10 * time.Second,
+		Pingback:     receiver,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "early", data.ID)
+}
+
+func TestPingbackReceiverClose(t *testing.T) {
+	wpt := newTestClient(t, testRoutes{
+		"/jsonResult.php": respond(`{"statusCode": 200, "data": {"id": "unread"}}`),
+	})
+	receiver := wpt.NewPingbackReceiver("http://example.com/pingback")
+	receiver.Expect("unread")
+	receiver.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pingback?id=unread", nil))
+
+	// Nobody reads results, delivery must not block forever after Close
+	done := make(chan struct{})
+	go func() {
+		receiver.deliver("unread")
+		close(done)
+	}()
+	receiver.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("delivery is blocked after Close")
+	}
+}
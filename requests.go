@@ -0,0 +1,185 @@
+package webpagetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// flexNumber is a number, that WebPageTest may send as a number, as a string
+// with number or as an empty string, depending on version of server and agent
+type flexNumber float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *flexNumber) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		// Not a number at all, like "" or "-", treat it as missing
+		*n = 0
+		return nil
+	}
+	*n = flexNumber(value)
+	return nil
+}
+
+// flexString is a string, that WebPageTest may send as a number
+type flexString string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *flexString) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*s = ""
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		*s = flexString(b)
+		return nil
+	}
+	*s = flexString(value)
+	return nil
+}
+
+// RequestTimings are phases of request, relative to its start.
+// Phases that are not applicable (like DNS for reused connection) are 0
+type RequestTimings struct {
+	// Start of request relative to start of step
+	Start time.Duration
+	DNS   time.Duration
+	// TCP connection, without SSL negotiation
+	Connect time.Duration
+	SSL     time.Duration
+	// Time to first byte of response after request was sent
+	TTFB     time.Duration
+	Download time.Duration
+	// Whole request from start of DNS lookup to end of download
+	Total time.Duration
+}
+
+// Request is one http request of test step
+type Request struct {
+	Index        int
+	Number       int
+	ID           string
+	Method       string
+	Host         string
+	URL          string
+	FullURL      string
+	IP           string
+	Protocol     string
+	Priority     string
+	ResponseCode int
+
+	// Content
+	ContentType     string
+	ContentEncoding string
+	ObjectSize      int
+	// Bytes sent and received over network, including headers
+	BytesIn  int
+	BytesOut int
+
+	// Cache
+	Expires      string
+	CacheControl string
+	CacheTime    int
+	CDNProvider  string
+
+	// Initiator info
+	Initiator         string
+	InitiatorType     string
+	InitiatorFunction string
+	InitiatorLine     int
+	InitiatorColumn   int
+
+	IsSecure  bool
+	WasPushed bool
+
+	Timings RequestTimings
+	Headers Headers
+}
+
+// ms converts WebPageTest milliseconds to duration, negative values mean "not applicable"
+func ms(value flexNumber) time.Duration {
+	if value < 0 {
+		return 0
+	}
+	return time.Duration(float64(value) * float64(time.Millisecond))
+}
+
+func (r jsonRequest) toRequest() Request {
+	return Request{
+		Index:        int(r.Index),
+		Number:       int(r.Number),
+		ID:           string(r.RequestID),
+		Method:       r.Method,
+		Host:         r.Host,
+		URL:          r.URL,
+		FullURL:      r.FullURL,
+		IP:           r.IP,
+		Protocol:     r.Protocol,
+		Priority:     r.Priority,
+		ResponseCode: int(r.ResponseCode),
+
+		ContentType:     r.ContentType,
+		ContentEncoding: r.ContentEncoding,
+		ObjectSize:      int(r.ObjectSize),
+		BytesIn:         int(r.BytesIn),
+		BytesOut:        int(r.BytesOut),
+
+		Expires:      string(r.Expires),
+		CacheControl: r.CacheControl,
+		CacheTime:    int(r.CacheTime),
+		CDNProvider:  r.CDNProvider,
+
+		Initiator:         r.Initiator,
+		InitiatorType:     r.InitiatorType,
+		InitiatorFunction: r.InitiatorFunction,
+		InitiatorLine:     int(r.InitiatorLine),
+		InitiatorColumn:   int(r.InitiatorColumn),
+
+		IsSecure:  r.IsSecure == 1,
+		WasPushed: r.WasPushed == 1,
+
+		Timings: RequestTimings{
+			Start:    ms(r.AllStart),
+			DNS:      ms(r.DNS),
+			Connect:  ms(r.Connect),
+			SSL:      ms(r.SSL),
+			TTFB:     ms(r.TTFB),
+			Download: ms(r.Download),
+			Total:    ms(r.All),
+		},
+		Headers: r.Headers,
+	}
+}
+
+// parseRequests handles "requests" of step, that is a number of requests by
+// default and a list of requests if result was requested with requests=1
+func (ts *TestStep) parseRequests(raw json.RawMessage) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '[' {
+		var count flexNumber
+		if err := count.UnmarshalJSON(raw); err != nil {
+			return err
+		}
+		ts.RequestsCount = int(count)
+		return nil
+	}
+
+	var requests []jsonRequest
+	if err := json.Unmarshal(raw, &requests); err != nil {
+		return err
+	}
+	ts.Requests = make([]Request, 0, len(requests))
+	for _, request := range requests {
+		ts.Requests = append(ts.Requests, request.toRequest())
+	}
+	ts.RequestsCount = len(ts.Requests)
+	return nil
+}
@@ -0,0 +1,64 @@
+package webpagetest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testResultWithRequests = `{"statusCode": 200, "statusText": "Test Complete", "data": {
+	"id": "171113_2M_S",
+	"runs": {"1": {"firstView": {
+		"run": 1, "numSteps": 1, "step": 1, "loadTime": 2000,
+		"requests": [{
+			"ip_addr": "173.194.122.199", "method": "GET", "host": "google.com", "url": "/",
+			"full_url": "http://google.com/", "responseCode": "302", "protocol": "HTTP/2",
+			"request_id": "9", "index": 0, "number": 1, "bytesIn": "467", "bytesOut": "397",
+			"objectSize": "256", "expires": -1, "is_secure": "1", "was_pushed": "",
+			"dns_ms": "-1", "connect_ms": 26, "ssl_ms": "-1", "ttfb_ms": "43.5",
+			"download_ms": 0, "all_start": "50", "all_ms": 69,
+			"headers": {"request": ["GET / HTTP/1.1"], "response": ["HTTP/1.1 302 Found"]}
+		}]
+	}}}
+}}`
+
+func TestGetTestResultWithRequests(t *testing.T) {
+	wpt := newTestClient(t, testRoutes{
+		"/jsonResult.php": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "1", r.URL.Query().Get("requests"))
+			w.Write([]byte(testResultWithRequests))
+		},
+	})
+
+	result, err := wpt.GetTestResultWithOptions(context.Background(), "171113_2M_S", ResultOptions{Requests: true})
+	assert.Nil(t, err)
+
+	step := result.Runs["1"].FirstView.Steps[0]
+	assert.Equal(t, 1, step.RequestsCount)
+	assert.Len(t, step.Requests, 1)
+
+	request := step.Requests[0]
+	assert.Equal(t, "http://google.com/", request.FullURL)
+	assert.Equal(t, 302, request.ResponseCode)
+	assert.Equal(t, "9", request.ID)
+	assert.Equal(t, "-1", request.Expires)
+	assert.Equal(t, 467, request.BytesIn)
+	assert.True(t, request.IsSecure)
+	assert.False(t, request.WasPushed)
+	assert.Equal(t, time.Duration(0), request.Timings.DNS)
+	assert.Equal(t, 26*time.Millisecond, request.Timings.Connect)
+	assert.Equal(t, 43500*time.Microsecond, request.Timings.TTFB)
+	assert.Equal(t, 50*time.Millisecond, request.Timings.Start)
+	assert.Equal(t, []string{"HTTP/1.1 302 Found"}, request.Headers.Response)
+}
+
+func TestParsingRequestsCount(t *testing.T) {
+	var step TestStep
+	assert.Nil(t, step.UnmarshalJSON([]byte(`{"loadTime": 2000, "requests": 42}`)))
+	assert.Equal(t, 42, step.RequestsCount)
+	assert.Nil(t, step.Requests)
+	assert.Equal(t, 2000, step.LoadTime)
+}
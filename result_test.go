@@ -1,7 +1,7 @@
 package webpagetest
 
 import (
-	"fmt"
+	"encoding/json"
 	"io/ioutil"
 	"testing"
 
@@ -13,7 +13,86 @@ func TestParsingResultWithPlrAsString(t *testing.T) {
 	assert.Nil(t, err)
 	_, err = parseResultResponse(response)
 	if err != nil {
-		fmt.Errorf("ERROR: %v", err)
+		t.Errorf("ERROR: %v", err)
 	}
 	assert.Nil(t, err)
 }
+
+func TestParsingResultWithPlrAsNumber(t *testing.T) {
+	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsNumber.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+
+	step := result.Runs["1"].FirstView.Steps[0]
+	assert.Equal(t, 19687.5, step.FullyLoadedCPUms)
+	assert.Equal(t, 160, step.RequestsCount)
+
+	value, ok := step.UserTime("page content ready")
+	assert.True(t, ok)
+	assert.Equal(t, 29614.0, value)
+	assert.Equal(t, 29614.0, step.Metrics["userTime.page content ready"])
+	assert.Equal(t, 1, step.Domains["adservice.google.com"].Requests)
+}
+
+func TestParsingDynamicMetrics(t *testing.T) {
+	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+
+	step := result.Runs["1"].FirstView.Steps[0]
+	assert.Equal(t, 3100, step.Render)
+	assert.Equal(t, 3100.0, step.Metrics["chromeUserTiming.firstMeaningfulPaint"])
+
+	value, ok := step.UserTimingMeasure("measure_adobe_target_request_request_2_mbox_DT_PosterHome")
+	assert.True(t, ok)
+	assert.Equal(t, 509.0, value)
+	_, ok = step.UserTime("missing")
+	assert.False(t, ok)
+
+	// Custom metric "Images" must not be confused with "images"
+	images, ok := step.CustomMetric("Images")
+	assert.True(t, ok)
+	assert.Contains(t, images, "dertour-logo")
+	assert.Contains(t, step.Images.Waterfall, "waterfall")
+	depth, ok := step.CustomMetricFloat("Colordepth")
+	assert.True(t, ok)
+	assert.Equal(t, 24.0, depth)
+
+	assert.Equal(t, "2.2.3", step.DetectedApps["jQuery"])
+	assert.Contains(t, step.Detected["Analytics"], "Google Analytics")
+	assert.Equal(t, 1, step.Domains["dertour.de"].Connections)
+	assert.Equal(t, 14606, step.Breakdown["html"].Bytes)
+}
+
+func TestTestStepFloatMetrics(t *testing.T) {
+	var step TestStep
+	err := json.Unmarshal([]byte(`{"SpeedIndex": 3131.5, "render": 1200, "loadTime": 2e3, "userTime.hero": 1500.25}`), &step)
+	assert.Nil(t, err)
+	assert.Equal(t, 3132, step.SpeedIndex)
+	assert.Equal(t, 1200, step.Render)
+	assert.Equal(t, 2000, step.LoadTime)
+	assert.Equal(t, 3131.5, step.Metrics["SpeedIndex"])
+	assert.Equal(t, 1500.25, step.UserTimes["hero"])
+}
+
+func TestTestStepRoundTrip(t *testing.T) {
+	result := loadResult(t, "TestResultPlrAsString.json")
+	step := result.Runs["1"].FirstView.Steps[0]
+
+	data, err := json.Marshal(step)
+	assert.Nil(t, err)
+	var decoded TestStep
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+
+	// Custom metrics are the same, but json.Marshal escapes HTML in them
+	assert.Len(t, decoded.CustomMetrics, len(step.CustomMetrics))
+	for name := range step.CustomMetrics {
+		expected, _ := step.CustomMetric(name)
+		value, _ := decoded.CustomMetric(name)
+		assert.Equal(t, expected, value, name)
+	}
+	step.CustomMetrics, decoded.CustomMetrics = nil, nil
+	assert.Equal(t, step, decoded)
+}
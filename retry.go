@@ -0,0 +1,145 @@
+package webpagetest
+
+import (
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how client should retry failed requests.
+// Only idempotent GET requests are retried, so test will never be started twice
+type RetryPolicy struct {
+	// Maximum number of retries after first attempt, 0 disables retries
+	MaxRetries int
+	// Backoff before first retry, it doubles with every next retry.
+	// 0 means retry right away, unless server asks to wait with Retry-After
+	MinBackoff time.Duration
+	// Upper limit for backoff, including one requested by server with Retry-After
+	MaxBackoff time.Duration
+	// HTTP status codes that are worth retrying
+	RetryableStatuses []int
+}
+
+// DefaultRetryPolicy retries transient server errors and throttling up to 3 times
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+	RetryableStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// WithRetryPolicy enables retries of failed GET requests with given policy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(w *WebPageTest) {
+		w.retry = policy
+	}
+}
+
+// shouldRetry tells if request with given outcome should be retried
+func (p RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	for _, status := range p.RetryableStatuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns time to wait before given retry (0-based), with "equal jitter":
+// half of exponential backoff is fixed and other half is random
+func (p RetryPolicy) backoff(retry int, resp *http.Response) time.Duration {
+	var backoff time.Duration
+	if p.MinBackoff > 0 {
+		backoff = p.MinBackoff << uint(retry)
+		// Shift overflowed, backoff is as long as it can be
+		if retry >= 63 || backoff>>uint(retry) != p.MinBackoff {
+			backoff = math.MaxInt64
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff > 0 {
+		backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+
+	// Server knows better when we can come back, but we don't wait longer than MaxBackoff
+	if after := retryAfter(resp, time.Now()); after > backoff {
+		backoff = after
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// retryAfter parses Retry-After header of response, that can be either
+// number of seconds or HTTP date
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	header := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// do will send request to server with client's headers applied, respecting
+// rate limit and retry policy
+func (w *WebPageTest) do(req *http.Request) (*http.Response, error) {
+	for key, values := range w.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if w.userAgent != "" {
+		req.Header.Set("User-Agent", w.userAgent)
+	}
+
+	ctx := req.Context()
+	for retry := 0; ; retry++ {
+		if w.limiter != nil {
+			if err := w.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := w.client.Do(req)
+		if req.Method != http.MethodGet || retry >= w.retry.MaxRetries ||
+			ctx.Err() != nil || !w.retry.shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		backoff := w.retry.backoff(retry, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
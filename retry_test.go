@@ -0,0 +1,101 @@
+package webpagetest
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransientErrors(t *testing.T) {
+	var calls int32
+	unavailable := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"statusCode": 101, "statusText": "Test Pending", "data": {"statusCode": 101}}`))
+	}
+
+	policy := DefaultRetryPolicy
+	policy.MinBackoff = time.Millisecond
+	wpt := newTestClient(t, testRoutes{"/testStatus.php": unavailable, "/runtest.php": unavailable},
+		WithRetryPolicy(policy))
+
+	status, err := wpt.GetTestStatus("161128_R3_2")
+	assert.Nil(t, err)
+	assert.Equal(t, 101, status.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// POST to runtest.php is never retried
+	atomic.StoreInt32(&calls, 0)
+	_, err = wpt.RunTest(TestSettings{URL: "https://example.com"})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(100, 2)
+	ctx := context.Background()
+
+	started := time.Now()
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, limiter.Wait(ctx))
+	}
+	// Two requests are covered by burst, two more have to wait ~10ms each
+	assert.True(t, time.Since(started) >= 15*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	limiter = NewRateLimiter(0.001, 1)
+	assert.Nil(t, limiter.Wait(ctx))
+	assert.Equal(t, context.Canceled, limiter.Wait(ctx))
+}
+
+func TestRetryAfter(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Minute}
+	resp := &http.Response{Header: http.Header{"Retry-After": {"3600"}}}
+	assert.Equal(t, time.Minute, policy.backoff(0, resp))
+
+	resp.Header.Set("Retry-After", "5")
+	assert.Equal(t, 5*time.Second, policy.backoff(0, resp))
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	resp.Header.Set("Retry-After", now.Add(30*time.Second).Format(http.TimeFormat))
+	assert.Equal(t, 30*time.Second, retryAfter(resp, now))
+
+	resp.Header.Set("Retry-After", "soon")
+	assert.Equal(t, time.Duration(0), retryAfter(resp, now))
+}
+
+func TestUnlimitedRateLimiter(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		limiter := NewRateLimiter(rate, 1)
+		started := time.Now()
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, limiter.Wait(context.Background()))
+		}
+		assert.True(t, time.Since(started) < time.Second)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	// No MinBackoff means no wait
+	policy := RetryPolicy{MaxBackoff: time.Minute}
+	for retry := 0; retry < 5; retry++ {
+		assert.Equal(t, time.Duration(0), policy.backoff(retry, nil))
+	}
+
+	policy = RetryPolicy{MinBackoff: time.Second, MaxBackoff: time.Minute}
+	backoff := policy.backoff(2, nil)
+	assert.True(t, backoff >= 2*time.Second && backoff <= 4*time.Second, backoff)
+
+	// Overflow of exponential backoff falls back to MaxBackoff
+	for _, retry := range []int{40, 62, 63, 100} {
+		backoff := policy.backoff(retry, nil)
+		assert.True(t, backoff >= 30*time.Second && backoff <= time.Minute, "retry %d: %v", retry, backoff)
+	}
+}
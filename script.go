@@ -0,0 +1,227 @@
+package webpagetest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// https://docs.webpagetest.org/scripting/
+
+// Command is one command of WebPageTest script, like "navigate" with URL as
+// an argument
+type Command struct {
+	Name string
+	Args []string
+}
+
+// String serializes command as a line of script, arguments are separated by tabs
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), "\t")
+}
+
+// Script is WebPageTest script, built command by command:
+//
+//	script := webpagetest.NewScript().
+//		LogData(false).
+//		Navigate("https://example.com/login").
+//		SetValue("id=user", "name").
+//		LogData(true).
+//		SetEventName("Login").
+//		ClickAndWait("id=submit")
+//	text, err := script.MarshalText()
+//	if err != nil {
+//		// Unknown command or invalid arguments, err is ScriptErrors
+//	}
+//	settings.Script = string(text)
+type Script struct {
+	Commands []Command
+}
+
+// NewScript creates empty script
+func NewScript() *Script {
+	return &Script{}
+}
+
+// Command will add any command with given arguments to script. Commands that
+// ParseScript does not know fail Validate, String can be used to bypass it
+func (s *Script) Command(name string, args ...string) *Script {
+	s.Commands = append(s.Commands, Command{Name: name, Args: args})
+	return s
+}
+
+// Navigate will navigate browser to URL and wait for page to load
+func (s *Script) Navigate(url string) *Script {
+	return s.Command("navigate", url)
+}
+
+// SetEventName will name next step, name is available as TestStep.EventName
+func (s *Script) SetEventName(name string) *Script {
+	return s.Command("setEventName", name)
+}
+
+// LogData will enable or disable recording of following steps
+func (s *Script) LogData(enabled bool) *Script {
+	return s.Command("logData", boolArg(enabled))
+}
+
+// CombineSteps will combine next count steps into one, 0 means all steps
+func (s *Script) CombineSteps(count int) *Script {
+	if count <= 0 {
+		return s.Command("combineSteps")
+	}
+	return s.Command("combineSteps", strconv.Itoa(count))
+}
+
+// Click will click element, like "id=submit" or "innerText=Login"
+func (s *Script) Click(selector string) *Script {
+	return s.Command("click", selector)
+}
+
+// ClickAndWait will click element and wait for browser activity to complete
+func (s *Script) ClickAndWait(selector string) *Script {
+	return s.Command("clickAndWait", selector)
+}
+
+// SetValue will set value of element, like input field
+func (s *Script) SetValue(selector, value string) *Script {
+	return s.Command("setValue", selector, value)
+}
+
+// SubmitForm will submit form and wait for browser activity to complete
+func (s *Script) SubmitForm(selector string) *Script {
+	return s.Command("submitForm", selector)
+}
+
+// Exec will execute javascript
+func (s *Script) Exec(script string) *Script {
+	return s.Command("exec", script)
+}
+
+// ExecAndWait will execute javascript and wait for browser activity to complete
+func (s *Script) ExecAndWait(script string) *Script {
+	return s.Command("execAndWait", script)
+}
+
+// Sleep will pause script for given number of seconds
+func (s *Script) Sleep(seconds int) *Script {
+	return s.Command("sleep", strconv.Itoa(seconds))
+}
+
+// SetTimeout will change timeout of following steps, in seconds
+func (s *Script) SetTimeout(seconds int) *Script {
+	return s.Command("setTimeout", strconv.Itoa(seconds))
+}
+
+// SetCookie will set cookie for given path, like "http://example.com" and "name=value"
+func (s *Script) SetCookie(path, cookie string) *Script {
+	return s.Command("setCookie", path, cookie)
+}
+
+// SetDNSName will make hostname to resolve as other one (CNAME-like override)
+func (s *Script) SetDNSName(hostname, target string) *Script {
+	return s.Command("setDnsName", hostname, target)
+}
+
+// SetDNS will make hostname to resolve to given IP address
+func (s *Script) SetDNS(hostname, ip string) *Script {
+	return s.Command("setDns", hostname, ip)
+}
+
+// SetHeader will set header on all following requests, like "Host: example.com"
+func (s *Script) SetHeader(header string) *Script {
+	return s.Command("setHeader", header)
+}
+
+// AddHeader will add header to all following requests
+func (s *Script) AddHeader(header string) *Script {
+	return s.Command("addHeader", header)
+}
+
+// ResetHeaders will remove all headers added by SetHeader and AddHeader
+func (s *Script) ResetHeaders() *Script {
+	return s.Command("resetHeaders")
+}
+
+// Block will block requests with URLs containing any of substrings
+func (s *Script) Block(substrings ...string) *Script {
+	return s.Command("block", strings.Join(substrings, " "))
+}
+
+// BlockDomains will block requests to given domains
+func (s *Script) BlockDomains(domains ...string) *Script {
+	return s.Command("blockDomains", strings.Join(domains, " "))
+}
+
+// SetUserAgent will override user agent string
+func (s *Script) SetUserAgent(userAgent string) *Script {
+	return s.Command("setUserAgent", userAgent)
+}
+
+// SetViewportSize will resize viewport to given size in css pixels
+func (s *Script) SetViewportSize(width, height int) *Script {
+	return s.Command("setViewportSize", strconv.Itoa(width), strconv.Itoa(height))
+}
+
+// WaitForComplete will wait for browser activity to complete
+func (s *Script) WaitForComplete() *Script {
+	return s.Command("waitForComplete")
+}
+
+// String serializes script, one command per line. Script is not validated,
+// use MarshalText to get error instead of broken script
+func (s *Script) String() string {
+	lines := make([]string, 0, len(s.Commands))
+	for _, command := range s.Commands {
+		lines = append(lines, command.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// MarshalText implements encoding.TextMarshaler, unlike String it fails if
+// script is not valid
+func (s *Script) MarshalText() ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(s.String()), nil
+}
+
+// Validate will check that every command is known and has valid arguments,
+// like positive viewport size, and that it can be serialized (javascript with
+// line breaks can not). Returned error is ScriptErrors, line of issue is the
+// line of command in String()
+func (s *Script) Validate() error {
+	var errs ScriptErrors
+	for idx, command := range s.Commands {
+		if command.Name == "" || strings.ContainsAny(command.Name, "\t\r\n") {
+			errs = append(errs, ScriptIssue{Line: idx + 1, Command: command.Name, Message: "invalid command name"})
+			continue
+		}
+		if hasLineBreaks(command.Args) {
+			errs = append(errs, ScriptIssue{Line: idx + 1, Command: command.Name,
+				Message: "arguments can not contain tabs or line breaks"})
+			continue
+		}
+		errs = append(errs, checkCommand(command, idx+1)...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func hasLineBreaks(args []string) bool {
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "\t\r\n") {
+			return true
+		}
+	}
+	return false
+}
+
+func boolArg(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,105 @@
+package webpagetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptBuilder(t *testing.T) {
+	script := NewScript().
+		LogData(false).
+		Navigate("https://example.com/login").
+		SetValue("id=user", "name").
+		LogData(true).
+		SetEventName("Login").
+		CombineSteps(0).
+		ClickAndWait("id=submit").
+		Block("ads.js", "tracker").
+		SetViewportSize(1280, 720)
+
+	expected := "logData\t0\n" +
+		"navigate\thttps://example.com/login\n" +
+		"setValue\tid=user\tname\n" +
+		"logData\t1\n" +
+		"setEventName\tLogin\n" +
+		"combineSteps\n" +
+		"clickAndWait\tid=submit\n" +
+		"block\tads.js tracker\n" +
+		"setViewportSize\t1280\t720"
+	assert.Equal(t, expected, script.String())
+
+	text, err := script.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(text))
+
+	_, err = NewScript().Exec("let a = 1;\nlet b = 2;").MarshalText()
+	assert.NotNil(t, err)
+}
+
+func TestScriptBuilders(t *testing.T) {
+	script := NewScript().
+		Command("setActivityTimeout", "5000").
+		Navigate("https://example.com").
+		SetEventName("Home").
+		LogData(true).
+		CombineSteps(2).
+		Click("id=menu").
+		ClickAndWait("id=next").
+		SetValue("name=q", "shoes").
+		SubmitForm("id=search").
+		Exec("window.scrollTo(0, 1000)").
+		ExecAndWait("document.forms[0].submit()").
+		Sleep(5).
+		SetTimeout(60).
+		SetCookie("https://example.com", "session=1").
+		SetDNSName("example.com", "cdn.example.com").
+		SetDNS("example.com", "127.0.0.1").
+		SetHeader("Host: example.com").
+		AddHeader("X-Test: 1").
+		ResetHeaders().
+		Block("ads.js").
+		BlockDomains("ads.example.com", "tracker.example.com").
+		SetUserAgent("Mozilla/5.0").
+		SetViewportSize(375, 667).
+		WaitForComplete()
+	assert.Nil(t, script.Validate())
+
+	// Every builder makes exactly one command, that survives round trip
+	assert.Len(t, script.Commands, 24)
+	parsed, err := ParseScript(script.String())
+	assert.Nil(t, err)
+	assert.Equal(t, script, parsed)
+	assert.Equal(t, Command{Name: "combineSteps", Args: []string{"2"}}, script.Commands[4])
+	assert.Equal(t, Command{Name: "setDnsName", Args: []string{"example.com", "cdn.example.com"}}, script.Commands[14])
+	assert.Equal(t, "blockDomains\tads.example.com tracker.example.com", script.Commands[20].String())
+}
+
+func TestScriptValidate(t *testing.T) {
+	tests := []struct {
+		script  *Script
+		message string
+	}{
+		{NewScript().Sleep(-5), "argument 1 is out of range: -5"},
+		{NewScript().SetViewportSize(-1, 0), "argument 1 is out of range: -1"},
+		{NewScript().SetViewportSize(1280, 0), "argument 2 is out of range: 0"},
+		{NewScript().SetTimeout(0), "argument 1 is out of range: 0"},
+		{NewScript().Navigate(""), "argument 1 is empty"},
+		{NewScript().Block(), "argument 1 is empty"},
+		{NewScript().Command("teleport", "mars"), "unknown command"},
+		{NewScript().Command("navigate"), "expected 1 arguments, got 0"},
+		{NewScript().Command(""), "invalid command name"},
+		{NewScript().Exec("let a = 1;\nlet b = 2;"), "arguments can not contain tabs or line breaks"},
+	}
+	for _, test := range tests {
+		err := test.script.Validate()
+		var errs ScriptErrors
+		if assert.True(t, errors.As(err, &errs), test.message) {
+			assert.Equal(t, test.message, errs[0].Message)
+		}
+
+		_, err = test.script.MarshalText()
+		assert.NotNil(t, err, test.message)
+	}
+}
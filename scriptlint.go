@@ -0,0 +1,313 @@
+package webpagetest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// scriptCommand describes arguments of known script command
+type scriptCommand struct {
+	minArgs int
+	maxArgs int
+	// Indexes of arguments, that must be non-negative integer numbers
+	numeric []int
+}
+
+// Known commands by lowercase name, WebPageTest treats names case-insensitive
+var scriptCommands = map[string]scriptCommand{
+	"navigate":           {1, 1, nil},
+	"seteventname":       {1, 1, nil},
+	"logdata":            {1, 1, []int{0}},
+	"combinesteps":       {0, 1, []int{0}},
+	"click":              {1, 1, nil},
+	"clickandwait":       {1, 1, nil},
+	"sendclick":          {1, 1, nil},
+	"sendclickandwait":   {1, 1, nil},
+	"setvalue":           {2, 2, nil},
+	"settextvalue":       {2, 2, nil},
+	"setinnertext":       {2, 2, nil},
+	"setinnerhtml":       {2, 2, nil},
+	"selectvalue":        {2, 2, nil},
+	"type":               {1, 1, nil},
+	"keypress":           {1, 1, nil},
+	"submitform":         {1, 1, nil},
+	"exec":               {1, 1, nil},
+	"execandwait":        {1, 1, nil},
+	"sleep":              {1, 1, []int{0}},
+	"settimeout":         {1, 1, []int{0}},
+	"setactivitytimeout": {1, 1, []int{0}},
+	"waitfor":            {1, 1, nil},
+	"waitforcomplete":    {0, 0, nil},
+	"waitinterval":       {1, 1, []int{0}},
+	"setcookie":          {2, 2, nil},
+	"setdns":             {2, 2, nil},
+	"setdnsname":         {2, 2, nil},
+	"overridehost":       {2, 2, nil},
+	"setheader":          {1, 2, nil},
+	"addheader":          {1, 2, nil},
+	"resetheaders":       {0, 0, nil},
+	"block":              {1, 1, nil},
+	"blockdomains":       {1, 1, nil},
+	"blockdomainsexcept": {1, 1, nil},
+	"setuseragent":       {1, 1, nil},
+	"setviewportsize":    {2, 2, []int{0, 1}},
+	"setbrowsersize":     {2, 2, []int{0, 1}},
+	"setlocation":        {1, 2, nil},
+	"setabm":             {1, 1, []int{0}},
+	"expirecache":        {0, 1, []int{0}},
+	"clearcache":         {0, 0, nil},
+	"mininterval":        {2, 2, nil},
+	"endinterval":        {0, 0, nil},
+	"firefoxpref":        {2, 2, nil},
+}
+
+// Commands, which numeric arguments must be greater than zero
+var positiveArgs = map[string]bool{
+	"settimeout":      true,
+	"setviewportsize": true,
+	"setbrowsersize":  true,
+}
+
+// ScriptIssue is error or warning about script line
+type ScriptIssue struct {
+	// 1-based line of script, for built scripts it is index of command + 1
+	Line    int
+	Command string
+	Message string
+	// Warnings are likely mistakes, errors are commands WebPageTest can not run
+	Warning bool
+}
+
+// String gives issue in "line 3: setValue: expected 2 arguments, got 1" form
+func (i ScriptIssue) String() string {
+	prefix := ""
+	if i.Warning {
+		prefix = "warning: "
+	}
+	if i.Command == "" {
+		return fmt.Sprintf("%sline %d: %s", prefix, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%sline %d: %s: %s", prefix, i.Line, i.Command, i.Message)
+}
+
+// ScriptErrors is error with all errors found in script
+type ScriptErrors []ScriptIssue
+
+func (e ScriptErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, issue := range e {
+		messages = append(messages, issue.String())
+	}
+	return "invalid script: " + strings.Join(messages, "; ")
+}
+
+// ParseScript will parse script, one command per line with arguments
+// separated by tabs. Empty lines and "//" comments are skipped, so round trip
+// is command-level only: ParseScript(script.String()) gives the same commands,
+// but String() of parsed script has no comments. Trailing tabs are ignored.
+// Returned error is ScriptErrors; warnings are available with LintScript
+func ParseScript(text string) (*Script, error) {
+	script, _, issues := parseScript(text)
+
+	var scriptErrors ScriptErrors
+	for _, issue := range issues {
+		if !issue.Warning {
+			scriptErrors = append(scriptErrors, issue)
+		}
+	}
+	if len(scriptErrors) > 0 {
+		return script, scriptErrors
+	}
+	return script, nil
+}
+
+// LintScript will return all errors and warnings about script
+func LintScript(text string) []ScriptIssue {
+	script, lines, issues := parseScript(text)
+	issues = append(issues, lintCommands(script.Commands, lines)...)
+	sortIssues(issues)
+	return issues
+}
+
+// Lint will return all errors and warnings about script, line of issue
+// is the line of command in String()
+func (s *Script) Lint() []ScriptIssue {
+	lines := make([]int, len(s.Commands))
+	var issues []ScriptIssue
+	for idx, command := range s.Commands {
+		lines[idx] = idx + 1
+		issues = append(issues, checkCommand(command, idx+1)...)
+	}
+	issues = append(issues, lintCommands(s.Commands, lines)...)
+	sortIssues(issues)
+	return issues
+}
+
+func parseScript(text string) (*Script, []int, []ScriptIssue) {
+	script := NewScript()
+	var lines []int
+	var issues []ScriptIssue
+
+	for idx, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		// Trailing tabs (often left by editors) are not empty arguments
+		for len(fields) > 1 && strings.TrimSpace(fields[len(fields)-1]) == "" {
+			fields = fields[:len(fields)-1]
+		}
+		command := Command{Name: strings.TrimSpace(fields[0]), Args: fields[1:]}
+		if len(command.Args) == 0 {
+			command.Args = nil
+		}
+		script.Commands = append(script.Commands, command)
+		lines = append(lines, idx+1)
+		issues = append(issues, checkCommand(command, idx+1)...)
+	}
+	return script, lines, issues
+}
+
+// checkCommand will check that command is known and has right arguments
+func checkCommand(command Command, line int) []ScriptIssue {
+	issue := func(message string, args ...interface{}) []ScriptIssue {
+		return []ScriptIssue{{Line: line, Command: command.Name, Message: fmt.Sprintf(message, args...)}}
+	}
+
+	known, ok := scriptCommands[strings.ToLower(command.Name)]
+	if !ok {
+		// Common mistake is to separate arguments with spaces
+		if name := strings.Fields(command.Name); len(name) > 1 {
+			if _, ok := scriptCommands[strings.ToLower(name[0])]; ok {
+				return issue("arguments must be separated by tabs, not spaces")
+			}
+		}
+		return issue("unknown command")
+	}
+
+	if len(command.Args) < known.minArgs || len(command.Args) > known.maxArgs {
+		if known.minArgs == known.maxArgs {
+			return issue("expected %d arguments, got %d", known.minArgs, len(command.Args))
+		}
+		return issue("expected %d to %d arguments, got %d", known.minArgs, known.maxArgs, len(command.Args))
+	}
+	for idx := 0; idx < known.minArgs; idx++ {
+		if strings.TrimSpace(command.Args[idx]) == "" {
+			return issue("argument %d is empty", idx+1)
+		}
+	}
+	for _, idx := range known.numeric {
+		if idx >= len(command.Args) {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(command.Args[idx]))
+		if err != nil {
+			return issue("argument %d must be a number, got %q", idx+1, command.Args[idx])
+		}
+		if value < 0 || (value == 0 && positiveArgs[strings.ToLower(command.Name)]) {
+			return issue("argument %d is out of range: %d", idx+1, value)
+		}
+	}
+	return nil
+}
+
+// isStepCommand tells if command produces a step in results
+func isStepCommand(command Command) bool {
+	name := strings.ToLower(command.Name)
+	return name == "navigate" || name == "submitform" || strings.HasSuffix(name, "andwait")
+}
+
+// lintCommands will find likely mistakes in valid commands
+func lintCommands(commands []Command, lines []int) []ScriptIssue {
+	var issues []ScriptIssue
+	warn := func(idx int, message string, args ...interface{}) {
+		issues = append(issues, ScriptIssue{
+			Line:    lines[idx],
+			Command: commands[idx].Name,
+			Message: fmt.Sprintf(message, args...),
+			Warning: true,
+		})
+	}
+
+	// recorded[i] and unrecorded[i] are numbers of recorded and not recorded
+	// steps from command i to the end
+	recorded := make([]int, len(commands)+1)
+	unrecorded := make([]int, len(commands)+1)
+	logging := make([]bool, len(commands))
+	enabled := true
+	for idx, command := range commands {
+		if strings.EqualFold(command.Name, "logData") && len(command.Args) == 1 {
+			enabled = strings.TrimSpace(command.Args[0]) != "0"
+		}
+		logging[idx] = enabled
+	}
+	for idx := len(commands) - 1; idx >= 0; idx-- {
+		recorded[idx], unrecorded[idx] = recorded[idx+1], unrecorded[idx+1]
+		if isStepCommand(commands[idx]) {
+			if logging[idx] {
+				recorded[idx]++
+			} else {
+				unrecorded[idx]++
+			}
+		}
+	}
+
+	steps := 0
+	pendingEvent := -1
+	eventNames := make(map[string]int)
+	for idx, command := range commands {
+		name := strings.ToLower(command.Name)
+		switch {
+		case name == "logdata" && len(command.Args) == 1 && !logging[idx]:
+			// Every logData 0, after which steps are run, but none is recorded
+			if (idx == 0 || logging[idx-1]) && recorded[idx] == 0 && unrecorded[idx] > 0 {
+				if recorded[0] == 0 {
+					warn(idx, "no steps are recorded, add logData 1 before measured steps")
+				} else {
+					warn(idx, "%d steps after it are not recorded, add logData 1 before measured steps", unrecorded[idx])
+				}
+			}
+		case name == "combinesteps":
+			count := 0
+			if len(command.Args) == 1 {
+				count, _ = strconv.Atoi(strings.TrimSpace(command.Args[0]))
+			}
+			if recorded[idx] == 0 {
+				warn(idx, "no recorded steps follow")
+			} else if count > recorded[idx] {
+				warn(idx, "combines %d steps, but only %d recorded steps follow", count, recorded[idx])
+			}
+		case name == "seteventname" && len(command.Args) == 1:
+			if pendingEvent >= 0 {
+				warn(pendingEvent, "event name is not used by any step")
+			}
+			pendingEvent = idx
+			if previous, ok := eventNames[command.Args[0]]; ok {
+				warn(idx, "event name %q is already used at line %d", command.Args[0], lines[previous])
+			} else {
+				eventNames[command.Args[0]] = idx
+			}
+		case isStepCommand(command):
+			steps++
+			if logging[idx] {
+				pendingEvent = -1
+			}
+		}
+	}
+	if pendingEvent >= 0 {
+		warn(pendingEvent, "event name is not used by any step")
+	}
+
+	if steps == 0 && len(commands) > 0 {
+		warn(0, "script has no steps, add navigate or other *AndWait command")
+	}
+	return issues
+}
+
+func sortIssues(issues []ScriptIssue) {
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+}
@@ -0,0 +1,108 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScript(t *testing.T) {
+	text := "// Login and open dashboard\n" +
+		"logData\t0\n" +
+		"navigate\thttps://example.com/login\n" +
+		"\n" +
+		"setValue\tid=user\tname\n" +
+		"logData\t1\n" +
+		"setEventName\tLogin\n" +
+		"clickAndWait\tid=submit\r\n" +
+		"combineSteps\n"
+
+	script, err := ParseScript(text)
+	assert.NotNil(t, script)
+	assert.Nil(t, err)
+	assert.Len(t, script.Commands, 7)
+	assert.Equal(t, Command{Name: "setValue", Args: []string{"id=user", "name"}}, script.Commands[2])
+	assert.Equal(t, Command{Name: "combineSteps"}, script.Commands[6])
+
+	// Round trip
+	again, err := ParseScript(script.String())
+	assert.Nil(t, err)
+	assert.Equal(t, script, again)
+
+	// Trailing tabs are not arguments
+	script, err = ParseScript("navigate\thttps://example.com\t\nresetHeaders\t \n")
+	assert.Nil(t, err)
+	assert.Equal(t, []Command{
+		{Name: "navigate", Args: []string{"https://example.com"}},
+		{Name: "resetHeaders"},
+	}, script.Commands)
+
+	built := NewScript().Navigate("https://example.com").SetEventName("Home").CombineSteps(2)
+	parsed, err := ParseScript(built.String())
+	assert.Nil(t, err)
+	assert.Equal(t, built, parsed)
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	_, err := ParseScript("navigate\thttps://example.com\nsetValue\tid=user\nsleep\tfive\nnavigate https://example.com\nfoo\tbar")
+	scriptErrors, ok := err.(ScriptErrors)
+	assert.True(t, ok)
+	assert.Equal(t, ScriptErrors{
+		{Line: 2, Command: "setValue", Message: "expected 2 arguments, got 1"},
+		{Line: 3, Command: "sleep", Message: `argument 1 must be a number, got "five"`},
+		{Line: 4, Command: "navigate https://example.com", Message: "arguments must be separated by tabs, not spaces"},
+		{Line: 5, Command: "foo", Message: "unknown command"},
+	}, scriptErrors)
+	assert.Equal(t, "line 2: setValue: expected 2 arguments, got 1", scriptErrors[0].String())
+}
+
+func TestLintScript(t *testing.T) {
+	issues := LintScript("logData\t0\n" +
+		"setEventName\tUnused\n" +
+		"navigate\thttps://example.com\n" +
+		"combineSteps\t2\n" +
+		"clickAndWait\tid=next\n")
+	assert.Equal(t, []string{
+		"warning: line 1: logData: no steps are recorded, add logData 1 before measured steps",
+		"warning: line 2: setEventName: event name is not used by any step",
+		"warning: line 4: combineSteps: no recorded steps follow",
+	}, issueStrings(issues))
+
+	issues = LintScript("setEventName\tHome\n" +
+		"navigate\thttps://example.com\n" +
+		"combineSteps\t3\n" +
+		"setEventName\tHome\n" +
+		"clickAndWait\tid=next\n" +
+		"sleep\t1\n")
+	assert.Equal(t, []string{
+		"warning: line 3: combineSteps: combines 3 steps, but only 1 recorded steps follow",
+		`warning: line 4: setEventName: event name "Home" is already used at line 1`,
+	}, issueStrings(issues))
+
+	// Steps after last logData 0 are never recorded
+	issues = LintScript("navigate\thttps://example.com\n" +
+		"logData\t0\n" +
+		"clickAndWait\tid=next\n" +
+		"logData\t1\n" +
+		"navigate\thttps://example.com/cart\n" +
+		"logData\t0\n" +
+		"clickAndWait\tid=checkout\n" +
+		"clickAndWait\tid=pay\n")
+	assert.Equal(t, []string{
+		"warning: line 6: logData: 2 steps after it are not recorded, add logData 1 before measured steps",
+	}, issueStrings(issues))
+
+	issues = NewScript().Exec("window.scrollTo(0, 100)").Command("wait", "1").Lint()
+	assert.Equal(t, []string{
+		"warning: line 1: exec: script has no steps, add navigate or other *AndWait command",
+		"line 2: wait: unknown command",
+	}, issueStrings(issues))
+}
+
+func issueStrings(issues []ScriptIssue) []string {
+	var result []string
+	for _, issue := range issues {
+		result = append(result, issue.String())
+	}
+	return result
+}
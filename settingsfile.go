@@ -0,0 +1,166 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseTestSettings will parse test settings in YAML or JSON. JSON uses names
+// of TestSettings fields ("ScreenWidth"), YAML uses the same names in lower
+// camel case ("screenWidth"):
+//
+//	url: https://example.com
+//	location: Dulles:Chrome.Cable
+//	runs: 5
+//	captureVideo: true
+func ParseTestSettings(data []byte) (*TestSettings, error) {
+	var settings TestSettings
+	if err := decodeSettings(data, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// LoadTestSettings will read test settings from YAML or JSON file
+func LoadTestSettings(path string) (*TestSettings, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := ParseTestSettings(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings file %s: %v", path, err)
+	}
+	return settings, nil
+}
+
+// SaveTestSettings will write test settings to file, as YAML if file has
+// ".yml" or ".yaml" extension and as JSON otherwise
+func SaveTestSettings(path string, settings TestSettings) error {
+	return saveSettings(path, settings)
+}
+
+// ParseTestSuite will parse suite of tests in YAML or JSON. Every test is
+// applied on top of defaults, so it can override any of them:
+//
+//	defaults:
+//	  location: Dulles:Chrome.Cable
+//	  runs: 5
+//	tests:
+//	  - url: https://example.com
+//	    label: home
+//	  - url: https://example.com/search?q=shoes
+//	    label: search
+//	    runs: 9
+func ParseTestSuite(data []byte) ([]TestSettings, error) {
+	var tests []TestSettings
+	if json.Valid(data) {
+		var suite struct {
+			Defaults json.RawMessage   `json:"defaults"`
+			Tests    []json.RawMessage `json:"tests"`
+		}
+		if err := json.Unmarshal(data, &suite); err != nil {
+			return nil, err
+		}
+		for idx, raw := range suite.Tests {
+			var settings TestSettings
+			if err := decodeJSON(suite.Defaults, &settings); err != nil {
+				return nil, fmt.Errorf("defaults: %v", err)
+			}
+			if err := decodeJSON(raw, &settings); err != nil {
+				return nil, fmt.Errorf("tests[%d]: %v", idx, err)
+			}
+			tests = append(tests, settings)
+		}
+	} else {
+		var suite struct {
+			Defaults yaml.Node   `yaml:"defaults"`
+			Tests    []yaml.Node `yaml:"tests"`
+		}
+		if err := yaml.Unmarshal(data, &suite); err != nil {
+			return nil, err
+		}
+		for idx, node := range suite.Tests {
+			var settings TestSettings
+			if err := decodeYAML(&suite.Defaults, &settings); err != nil {
+				return nil, fmt.Errorf("defaults: %v", err)
+			}
+			if err := decodeYAML(&node, &settings); err != nil {
+				return nil, fmt.Errorf("tests[%d]: %v", idx, err)
+			}
+			tests = append(tests, settings)
+		}
+	}
+
+	if len(tests) == 0 {
+		return nil, errors.New("suite has no tests")
+	}
+	return tests, nil
+}
+
+// LoadTestSuite will read suite of tests from YAML or JSON file
+func LoadTestSuite(path string) ([]TestSettings, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := ParseTestSuite(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid suite file %s: %v", path, err)
+	}
+	return tests, nil
+}
+
+// SaveTestSuite will write tests to file in format of LoadTestSuite, without
+// defaults, every test is saved with all its settings
+func SaveTestSuite(path string, tests []TestSettings) error {
+	return saveSettings(path, struct {
+		Tests []TestSettings `yaml:"tests" json:"tests"`
+	}{tests})
+}
+
+// decodeSettings decodes JSON or YAML into settings
+func decodeSettings(data []byte, settings *TestSettings) error {
+	if json.Valid(data) {
+		return json.Unmarshal(data, settings)
+	}
+	return yaml.Unmarshal(data, settings)
+}
+
+// decodeJSON decodes raw on top of existing values of settings, missing raw is ignored
+func decodeJSON(raw json.RawMessage, settings *TestSettings) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return json.Unmarshal(raw, settings)
+}
+
+// decodeYAML decodes node on top of existing values of settings, missing node is ignored
+func decodeYAML(node *yaml.Node, settings *TestSettings) error {
+	if node.Kind == 0 {
+		return nil
+	}
+	return node.Decode(settings)
+}
+
+func saveSettings(path string, value interface{}) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		data, err = yaml.Marshal(value)
+	default:
+		data, err = json.MarshalIndent(value, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
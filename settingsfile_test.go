@@ -0,0 +1,160 @@
+package webpagetest
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSuiteYAML = `
+defaults:
+  location: Dulles:Chrome.Cable
+  runs: 5
+  captureVideo: true
+tests:
+  - url: https://example.com
+    label: home
+  - url: https://example.com/search
+    runs: 9
+    captureVideo: false
+`
+
+const testSuiteJSON = `{
+	"defaults": {"Location": "Dulles:Chrome.Cable", "Runs": 5, "CaptureVideo": true},
+	"tests": [
+		{"URL": "https://example.com", "Label": "home"},
+		{"URL": "https://example.com/search", "Runs": 9, "CaptureVideo": false}
+	]
+}`
+
+func TestParseTestSuite(t *testing.T) {
+	for name, data := range map[string]string{"yaml": testSuiteYAML, "json": testSuiteJSON} {
+		t.Run(name, func(t *testing.T) {
+			tests, err := ParseTestSuite([]byte(data))
+			assert.Nil(t, err)
+			assert.Equal(t, []TestSettings{
+				{URL: "https://example.com", Label: "home", Location: "Dulles:Chrome.Cable", Runs: 5, CaptureVideo: true},
+				{URL: "https://example.com/search", Location: "Dulles:Chrome.Cable", Runs: 9},
+			}, tests)
+		})
+	}
+
+	_, err := ParseTestSuite([]byte("defaults:\n  runs: 3\n"))
+	assert.EqualError(t, err, "suite has no tests")
+	_, err = ParseTestSuite([]byte("tests:\n  - runs: three\n"))
+	assert.NotNil(t, err)
+}
+
+func TestSaveTestSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webpagetest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	settings := TestSettings{URL: "https://example.com", Runs: 3, ScreenWidth: 1280, Mobile: true, MobileDevice: "Pixel2"}
+	for _, name := range []string{"settings.yml", "settings.json"} {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, SaveTestSettings(path, settings))
+		loaded, err := LoadTestSettings(path)
+		assert.Nil(t, err)
+		assert.Equal(t, settings, *loaded)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "settings.yml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "screenWidth: 1280")
+
+	tests := []TestSettings{settings, {URL: "https://example.com/about", Label: "about"}}
+	path := filepath.Join(dir, "suite.yaml")
+	assert.Nil(t, SaveTestSuite(path, tests))
+	loaded, err := LoadTestSuite(path)
+	assert.Nil(t, err)
+	assert.Equal(t, tests, loaded)
+}
+
+func TestTestInfoToSettings(t *testing.T) {
+	info := TestInfo{
+		URL: "http://google.com", Runs: 3, Video: "on", Label: "test run",
+		Location: "Prague", Browser: "Chrome", Connectivity: "Cable",
+		BandwidthIn: 5000, BandwidthOut: 1000, Latency: 28, FirstViewOnly: 1,
+	}
+	assert.Equal(t, TestSettings{
+		URL: "http://google.com", Runs: 3, CaptureVideo: true, Label: "test run",
+		Location: "Prague:Chrome.Cable", FirstViewOnly: true,
+	}, info.ToSettings())
+
+	info.Connectivity = "Custom"
+	info.PacketLossRate = 1
+	settings := info.ToSettings()
+	assert.Equal(t, "Prague:Chrome.Custom", settings.Location)
+	assert.Equal(t, 5000, settings.BWDown)
+	assert.Equal(t, 1, settings.PacketLossRate)
+	assert.Nil(t, settings.Validate())
+}
+
+// testStatusComplete is response of testStatus.php from status.go
+const testStatusComplete = `{"statusCode": 200, "statusText": "Test Complete", "data": {
+	"statusCode": 200, "statusText": "Test Complete",
+	"id": "161118_62_db87f3f04fe6b52b8cf4481fcf32cc0a",
+	"testInfo": {
+		"url": "http://google.com", "runs": 1, "fvonly": 0, "web10": 0, "ignoreSSL": 0,
+		"video": "on", "label": "", "priority": 0, "block": "", "location": "Dulles",
+		"browser": "Chrome", "connectivity": "Cable", "bwIn": 5000, "bwOut": 1000,
+		"latency": 28, "plr": "0", "tcpdump": 0, "timeline": 0, "trace": 0, "bodies": 0,
+		"netlog": 0, "standards": 0, "noscript": 0, "pngss": 0, "iq": 0, "keepua": 0,
+		"mobile": 0, "addCmdLine": "", "scripted": 0
+	},
+	"testId": "161118_62_db87f3f04fe6b52b8cf4481fcf32cc0a", "runs": 1, "fvonly": 0,
+	"location": "Dulles", "testsExpected": 1, "testsCompleted": 1
+}}`
+
+func TestTestInfoRoundTrip(t *testing.T) {
+	wpt := newTestClient(t, testRoutes{"/testStatus.php": respond(testStatusComplete)})
+	status, err := wpt.GetTestStatus("161118_62_db87f3f04fe6b52b8cf4481fcf32cc0a")
+	assert.Nil(t, err)
+
+	params := status.TestInfo.ToSettings().GetFormParams()
+	assert.Equal(t, url.Values{
+		"f":            {"json"},
+		"url":          {"http://google.com"},
+		"runs":         {"1"},
+		"label":        {""},
+		"where":        {""},
+		"browser":      {""},
+		"location":     {"Dulles:Chrome.Cable"},
+		"medianMetric": {""},
+		"script":       {""},
+		"pingback":     {""},
+		"domelement":   {""},
+		"block":        {""},
+		"login":        {""},
+		"password":     {""},
+		"authType":     {""},
+		"notify":       {""},
+		"k":            {""},
+		"uastring":     {""},
+		"cmdline":      {""},
+		"custom":       {""},
+		"tester":       {""},
+		"affinity":     {""},
+		"mobileDevice": {""},
+		"appendua":     {""},
+		"video":        {"1"},
+	}, params)
+
+	// Every reported option is carried over
+	info := status.TestInfo
+	info.Priority, info.Trace, info.Bodies, info.NetLog, info.Standards = 5, 1, 1, 1, 1
+	params = info.ToSettings().GetFormParams()
+	assert.Equal(t, "5", params.Get("priority"))
+	for _, name := range []string{"trace", "htmlbody", "netlog", "standards"} {
+		assert.Equal(t, "1", params.Get(name), name)
+	}
+
+	// Location names may contain dots
+	info = TestInfo{Location: "ec2.us-east-1", Browser: "Chrome", Connectivity: "3G"}
+	assert.Equal(t, "ec2.us-east-1:Chrome.3G", info.ToSettings().Location)
+}
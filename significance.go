@@ -0,0 +1,296 @@
+package webpagetest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Samples larger than this use normal approximation instead of exact distribution of U
+const maxExactSamples = 30
+
+// MannWhitneyResult is result of two-sided Mann-Whitney U test
+type MannWhitneyResult struct {
+	// U statistic of baseline sample: number of pairs where baseline value is
+	// greater than candidate one (ties count as half)
+	U float64
+	// Z score of normal approximation, 0 if Exact
+	Z      float64
+	PValue float64
+	// Exact is true if PValue was calculated from exact distribution of U
+	// (small samples without ties)
+	Exact bool
+	// Rank-biserial correlation from -1 to 1, positive values mean that
+	// candidate values tend to be greater than baseline ones
+	RankBiserial float64
+}
+
+// MannWhitneyU will test if baseline and candidate values come from the same distribution
+func MannWhitneyU(baseline, candidate []float64) (MannWhitneyResult, error) {
+	n1, n2 := len(baseline), len(candidate)
+	if n1 == 0 || n2 == 0 {
+		return MannWhitneyResult{}, errors.New("both samples must be non-empty")
+	}
+
+	type sample struct {
+		value    float64
+		baseline bool
+	}
+	samples := make([]sample, 0, n1+n2)
+	for _, value := range baseline {
+		samples = append(samples, sample{value, true})
+	}
+	for _, value := range candidate {
+		samples = append(samples, sample{value, false})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].value < samples[j].value })
+
+	// Average ranks for ties, and tie correction for variance
+	var rankSum, ties float64
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].value == samples[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if samples[k].baseline {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	product := float64(n1 * n2)
+	result := MannWhitneyResult{U: rankSum - float64(n1*(n1+1))/2}
+	result.RankBiserial = (product - 2*result.U) / product
+
+	if ties == 0 && n1 <= maxExactSamples && n2 <= maxExactSamples {
+		result.Exact = true
+		result.PValue = exactMannWhitneyP(n1, n2, result.U)
+		return result, nil
+	}
+
+	n := float64(n1 + n2)
+	sigma := math.Sqrt(product / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		// All values are the same
+		result.PValue = 1
+		return result, nil
+	}
+	// With continuity correction
+	diff := result.U - product/2
+	if diff > 0 {
+		diff = math.Max(diff-0.5, 0)
+	} else {
+		diff = math.Min(diff+0.5, 0)
+	}
+	result.Z = diff / sigma
+	result.PValue = math.Min(1, math.Erfc(math.Abs(result.Z)/math.Sqrt2))
+	return result, nil
+}
+
+// exactMannWhitneyP will return two-sided p-value of U from exact distribution
+func exactMannWhitneyP(n1, n2 int, u float64) float64 {
+	// counts[m][n][k] is number of arrangements of m and n values with U = k,
+	// rolled into two rows by m
+	maxU := n1 * n2
+	prev := make([][]float64, n2+1)
+	for n := range prev {
+		prev[n] = make([]float64, maxU+1)
+		prev[n][0] = 1
+	}
+	for m := 1; m <= n1; m++ {
+		current := make([][]float64, n2+1)
+		current[0] = make([]float64, maxU+1)
+		current[0][0] = 1
+		for n := 1; n <= n2; n++ {
+			current[n] = make([]float64, maxU+1)
+			for k := 0; k <= m*n; k++ {
+				// Largest value is from first sample and is greater than all n values of second
+				if k >= n {
+					current[n][k] += prev[n][k-n]
+				}
+				current[n][k] += current[n-1][k]
+			}
+		}
+		prev = current
+	}
+
+	counts := prev[n2]
+	var total, lower, upper float64
+	for k, count := range counts {
+		total += count
+		if float64(k) <= u {
+			lower += count
+		}
+		if float64(k) >= u {
+			upper += count
+		}
+	}
+	return math.Min(1, 2*math.Min(lower, upper)/total)
+}
+
+// BootstrapOptions are options for bootstrap confidence interval
+type BootstrapOptions struct {
+	// Number of resamples (10000)
+	Iterations int
+	// Confidence level (0.95)
+	Confidence float64
+	// Statistic to compare, median of values if nil
+	Statistic func(values []float64) float64
+	// Seed of random generator, the same seed gives the same interval (1)
+	Seed int64
+}
+
+// BootstrapResult is confidence interval of difference of statistic
+// (candidate - baseline)
+type BootstrapResult struct {
+	Estimate   float64
+	Lower      float64
+	Upper      float64
+	Confidence float64
+}
+
+// Significant will return true if interval does not contain 0
+func (b BootstrapResult) Significant() bool {
+	return b.Lower > 0 || b.Upper < 0
+}
+
+func median(values []float64) float64 {
+	return NewStats(values).Median
+}
+
+// BootstrapCI will calculate percentile bootstrap confidence interval of
+// difference of statistic between candidate and baseline values
+func BootstrapCI(baseline, candidate []float64, options BootstrapOptions) (BootstrapResult, error) {
+	if len(baseline) == 0 || len(candidate) == 0 {
+		return BootstrapResult{}, errors.New("both samples must be non-empty")
+	}
+	if options.Iterations <= 0 {
+		options.Iterations = 10000
+	}
+	if options.Confidence <= 0 || options.Confidence >= 1 {
+		options.Confidence = 0.95
+	}
+	if options.Statistic == nil {
+		options.Statistic = median
+	}
+	if options.Seed == 0 {
+		options.Seed = 1
+	}
+
+	random := rand.New(rand.NewSource(options.Seed))
+	resample := func(values, buffer []float64) []float64 {
+		for i := range buffer {
+			buffer[i] = values[random.Intn(len(values))]
+		}
+		return buffer
+	}
+
+	baselineBuffer := make([]float64, len(baseline))
+	candidateBuffer := make([]float64, len(candidate))
+	diffs := make([]float64, options.Iterations)
+	for i := range diffs {
+		diffs[i] = options.Statistic(resample(candidate, candidateBuffer)) -
+			options.Statistic(resample(baseline, baselineBuffer))
+	}
+
+	stats := NewStats(diffs)
+	tail := (1 - options.Confidence) / 2 * 100
+	return BootstrapResult{
+		Estimate:   options.Statistic(candidate) - options.Statistic(baseline),
+		Lower:      stats.Percentile(tail),
+		Upper:      stats.Percentile(100 - tail),
+		Confidence: options.Confidence,
+	}, nil
+}
+
+// SignificanceOptions are options for CompareSignificance
+type SignificanceOptions struct {
+	// Compare repeat views instead of first views
+	Cached bool
+	// Significance level for p-value (0.05)
+	Alpha     float64
+	Bootstrap BootstrapOptions
+}
+
+// SignificanceResult is result of significance tests of one metric
+type SignificanceResult struct {
+	Metric    string
+	Baseline  Stats
+	Candidate Stats
+	// Relative difference of medians (0.1 is +10%), 0 if baseline median is 0
+	Relative    float64
+	MannWhitney MannWhitneyResult
+	Bootstrap   BootstrapResult
+	// Cohen's d, difference of means in pooled standard deviations
+	CohensD float64
+	// Significant is true if p-value of Mann-Whitney U test is less than Alpha
+	Significant bool
+}
+
+// MetricValues will return values of metric for given step (0-based) of
+// successful runs of all given results, so several tests can be aggregated
+func MetricValues(results []*ResultData, step int, metric string, cached bool) []float64 {
+	var values []float64
+	for _, result := range results {
+		values = append(values, runValues(result.viewValues(step, metric, cached))...)
+	}
+	return values
+}
+
+// CompareSignificance will test if difference of metrics between baseline and
+// candidate runs is statistically significant. Runs of several results on
+// each side are aggregated together
+func CompareSignificance(baseline, candidate []*ResultData, step int, metrics []string, options SignificanceOptions) ([]SignificanceResult, error) {
+	if options.Alpha <= 0 {
+		options.Alpha = 0.05
+	}
+
+	results := make([]SignificanceResult, 0, len(metrics))
+	for _, metric := range metrics {
+		baselineValues := MetricValues(baseline, step, metric, options.Cached)
+		candidateValues := MetricValues(candidate, step, metric, options.Cached)
+		if len(baselineValues) == 0 || len(candidateValues) == 0 {
+			return nil, fmt.Errorf("no successful runs with metric %q for step %d", metric, step)
+		}
+
+		result := SignificanceResult{
+			Metric:    metric,
+			Baseline:  NewStats(baselineValues),
+			Candidate: NewStats(candidateValues),
+		}
+		if result.Baseline.Median != 0 {
+			result.Relative = (result.Candidate.Median - result.Baseline.Median) / result.Baseline.Median
+		}
+		result.CohensD = cohensD(result.Baseline, result.Candidate)
+
+		var err error
+		if result.MannWhitney, err = MannWhitneyU(baselineValues, candidateValues); err != nil {
+			return nil, err
+		}
+		if result.Bootstrap, err = BootstrapCI(baselineValues, candidateValues, options.Bootstrap); err != nil {
+			return nil, err
+		}
+		result.Significant = result.MannWhitney.PValue < options.Alpha
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func cohensD(baseline, candidate Stats) float64 {
+	n1, n2 := float64(baseline.Count), float64(candidate.Count)
+	if n1+n2 <= 2 {
+		return 0
+	}
+	pooled := math.Sqrt(((n1-1)*baseline.StdDev*baseline.StdDev + (n2-1)*candidate.StdDev*candidate.StdDev) / (n1 + n2 - 2))
+	if pooled == 0 {
+		return 0
+	}
+	return (candidate.Mean - baseline.Mean) / pooled
+}
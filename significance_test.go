@@ -0,0 +1,81 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		baseline     []float64
+		candidate    []float64
+		u            float64
+		pValue       float64
+		exact        bool
+		rankBiserial float64
+	}{
+		{[]float64{1, 2, 3}, []float64{4, 5, 6}, 0, 0.1, true, 1},
+		{[]float64{10, 12, 11, 14}, []float64{13, 15, 16, 18, 17}, 1, 4.0 / 126, true, 0.9},
+		{[]float64{6, 5, 4}, []float64{1, 2, 3}, 9, 0.1, true, -1},
+		// Ties, normal approximation with z = -1.697
+		{[]float64{1, 2, 2, 3, 5}, []float64{2, 4, 6, 6, 7}, 4, 0.0897, false, 0.68},
+		{[]float64{1, 1}, []float64{1, 1}, 2, 1, false, 0},
+	}
+
+	for _, test := range tests {
+		result, err := MannWhitneyU(test.baseline, test.candidate)
+		assert.Nil(t, err)
+		assert.Equal(t, test.u, result.U)
+		assert.InDelta(t, test.pValue, result.PValue, 0.0001)
+		assert.Equal(t, test.exact, result.Exact)
+		assert.InDelta(t, test.rankBiserial, result.RankBiserial, 0.0001)
+	}
+
+	_, err := MannWhitneyU(nil, []float64{1})
+	assert.NotNil(t, err)
+}
+
+func TestBootstrapCI(t *testing.T) {
+	baseline := []float64{1000, 1010, 990, 1005, 995, 1002, 998}
+	candidate := []float64{1100, 1110, 1090, 1105, 1095, 1102, 1098}
+
+	result, err := BootstrapCI(baseline, candidate, BootstrapOptions{Iterations: 2000})
+	assert.Nil(t, err)
+	assert.Equal(t, 100.0, result.Estimate)
+	assert.True(t, result.Lower > 0 && result.Lower <= 100)
+	assert.True(t, result.Upper >= 100)
+	assert.True(t, result.Significant())
+
+	again, err := BootstrapCI(baseline, candidate, BootstrapOptions{Iterations: 2000})
+	assert.Nil(t, err)
+	assert.Equal(t, result, again)
+
+	result, err = BootstrapCI(baseline, baseline, BootstrapOptions{})
+	assert.Nil(t, err)
+	assert.False(t, result.Significant())
+}
+
+func TestCompareSignificance(t *testing.T) {
+	baseline := loadResult(t, "TestResultPlrAsNumber.json")
+	candidate := loadResult(t, "TestResultPlrAsNumber.json")
+	for key := range candidate.Runs {
+		setStep(candidate, key, false, func(step *TestStep) { step.Metrics["loadTime"] += 5000 })
+	}
+
+	results, err := CompareSignificance([]*ResultData{baseline}, []*ResultData{candidate, candidate}, 0,
+		[]string{"loadTime", "SpeedIndex"}, SignificanceOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+
+	assert.Equal(t, 5, results[0].Baseline.Count)
+	assert.Equal(t, 10, results[0].Candidate.Count)
+	assert.True(t, results[0].Significant)
+	assert.Equal(t, 5000.0, results[0].Bootstrap.Estimate)
+	assert.True(t, results[0].CohensD > 1)
+	assert.False(t, results[1].Significant)
+	assert.Equal(t, 0.0, results[1].Relative)
+
+	_, err = CompareSignificance([]*ResultData{baseline}, nil, 0, []string{"loadTime"}, SignificanceOptions{})
+	assert.NotNil(t, err)
+}
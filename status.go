@@ -1,8 +1,9 @@
 package webpagetest
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 )
@@ -127,6 +128,10 @@ type TestInfo struct {
 	Priority      int    `json:"priority"`
 	Location      string `json:"location"`
 	Browser       string `json:"browser"`
+	Block         string `json:"block"`
+	MedianMetric  string `json:"medianMetric"`
+	CmdLine       string `json:"addCmdLine"`
+	Script        string `json:"script"`
 
 	Connectivity string `json:"connectivity"`
 	BandwidthIn  int    `json:"bwIn"`
@@ -151,6 +156,59 @@ type TestInfo struct {
 	Scripted     int `json:"scripted"`
 }
 
+// ToSettings will return settings to run test again with the same parameters.
+// Settings that server does not report in testInfo, like API key, custom
+// headers or custom metrics, are left empty
+func (ti TestInfo) ToSettings() TestSettings {
+	settings := TestSettings{
+		URL:           ti.URL,
+		Label:         ti.Label,
+		Location:      ti.location(),
+		Runs:          ti.Runs,
+		Script:        ti.Script,
+		Block:         ti.Block,
+		CmdLine:       ti.CmdLine,
+		Priority:      ti.Priority,
+		MedianMetric:  ti.MedianMetric,
+		FirstViewOnly: ti.FirstViewOnly == 1,
+		Web10:         ti.Web10 == 1,
+		IgnoreSSL:     ti.IgnoreSSL == 1,
+		CaptureVideo:  ti.Video == "on" || ti.Video == "1",
+		TCPDump:       ti.Tcpdump == 1,
+		Timeline:      ti.Timeline == 1,
+		Trace:         ti.Trace == 1,
+		HTMLBody:      ti.Bodies == 1,
+		NetLog:        ti.NetLog == 1,
+		Standards:     ti.Standards == 1,
+		NoScript:      ti.NoScript == 1,
+		PNGScreenShot: ti.Pngss == 1,
+		ImageQuality:  ti.ImageQuality,
+		KeepUA:        ti.KeepUA == 1,
+		Mobile:        ti.Mobile == 1,
+	}
+
+	// Bandwidth of predefined profiles is implied by profile name
+	if ti.Connectivity == "Custom" {
+		settings.BWDown = ti.BandwidthIn
+		settings.BWUp = ti.BandwidthOut
+		settings.Latency = ti.Latency
+		settings.PacketLossRate = ti.PacketLossRate
+	}
+	return settings
+}
+
+// location builds "Dulles:Chrome.Cable" from location, browser and connectivity
+func (ti TestInfo) location() string {
+	location := ti.Location
+	if ti.Browser != "" {
+		location += ":" + ti.Browser
+	}
+	if ti.Connectivity != "" {
+		location += "." + ti.Connectivity
+	}
+	return location
+}
+
 type TestStatus struct {
 	StatusCode int    `json:"statusCode"`
 	StatusText string `json:"statusText"`
@@ -188,7 +246,12 @@ type jsonTestStatus struct {
 // StatusCode 200 indicates test is completed. 1XX means the test is still
 // in progress. And 4XX indicates some error.
 func (w *WebPageTest) GetTestStatus(testID string) (*TestStatus, error) {
-	body, err := w.query("/testStatus.php", url.Values{"test": []string{testID}})
+	return w.GetTestStatusContext(context.Background(), testID)
+}
+
+// GetTestStatusContext is like GetTestStatus but uses ctx for the underlying request
+func (w *WebPageTest) GetTestStatusContext(ctx context.Context, testID string) (*TestStatus, error) {
+	body, err := w.query(ctx, "/testStatus.php", url.Values{"test": []string{testID}})
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +269,13 @@ func (w *WebPageTest) GetTestStatus(testID string) (*TestStatus, error) {
 	}
 
 	if result.StatusCode > 200 {
-		return nil, fmt.Errorf("%s", result.StatusText)
+		return nil, &APIError{
+			Endpoint:   "/testStatus.php",
+			TestID:     testID,
+			HTTPStatus: http.StatusOK,
+			StatusCode: result.StatusCode,
+			StatusText: result.StatusText,
+		}
 	}
 
 	return &result.Data, nil
@@ -0,0 +1,91 @@
+package webpagetest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Step will return step by event name (set by "setEventName" in script)
+func (tv *TestView) Step(name string) (*TestStep, bool) {
+	for idx := range tv.Steps {
+		if tv.Steps[idx].EventName == name {
+			return &tv.Steps[idx], true
+		}
+	}
+	return nil, false
+}
+
+// StepIndex will return 0-based index of step by event name, it can be used
+// with methods that take step index. Returned error matches ErrStepNotFound
+func (rd *ResultData) StepIndex(name string) (int, error) {
+	for _, key := range rd.runKeys() {
+		run := rd.Runs[key]
+		for _, view := range []TestView{run.FirstView, run.RepeatView} {
+			for idx, step := range view.Steps {
+				if step.EventName == name {
+					return idx, nil
+				}
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: %q in test %s", ErrStepNotFound, name, rd.ID)
+}
+
+// StepNames will return event names of steps, in order
+func (rd *ResultData) StepNames() []string {
+	names := make([]string, rd.numSteps())
+	for _, key := range rd.runKeys() {
+		for idx, step := range rd.Runs[key].FirstView.Steps {
+			if names[idx] == "" {
+				names[idx] = step.EventName
+			}
+		}
+	}
+	return names
+}
+
+// GetMedianRunByName is like GetMedianRun, but takes event name of step
+func (rd *ResultData) GetMedianRunByName(name string, metric string) (*TestRun, error) {
+	step, err := rd.StepIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	return rd.GetMedianRun(step, metric)
+}
+
+// GetSummaryByName is like GetSummary, but takes event name of step
+func (rd *ResultData) GetSummaryByName(name string, metric string) (*MetricSummary, error) {
+	step, err := rd.StepIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	return rd.GetSummary(step, metric)
+}
+
+// numSteps will return number of steps in test
+func (rd *ResultData) numSteps() int {
+	var steps int
+	for _, run := range rd.Runs {
+		if len(run.FirstView.Steps) > steps {
+			steps = len(run.FirstView.Steps)
+		}
+	}
+	return steps
+}
+
+// runKeys will return keys of runs sorted by run number
+func (rd *ResultData) runKeys() []string {
+	values := make([]runValue, 0, len(rd.Runs))
+	for key := range rd.Runs {
+		number, _ := strconv.Atoi(key)
+		values = append(values, runValue{run: number, key: key})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].run < values[j].run })
+
+	keys := make([]string, 0, len(values))
+	for _, value := range values {
+		keys = append(keys, value.key)
+	}
+	return keys
+}
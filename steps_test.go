@@ -0,0 +1,73 @@
+package webpagetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// insertStep adds step with given event name before first step of every run and view
+func insertStep(result *ResultData, name string) {
+	for key, run := range result.Runs {
+		for _, view := range []*TestView{&run.FirstView, &run.RepeatView} {
+			step := view.Steps[0]
+			step.EventName = name
+			view.Steps = append([]TestStep{step}, view.Steps...)
+		}
+		result.Runs[key] = run
+	}
+}
+
+func TestStepIndex(t *testing.T) {
+	result := loadResult(t, "TestResultPlrAsNumber.json")
+	insertStep(result, "Login")
+	assert.Equal(t, []string{"Login", "Step 1"}, result.StepNames())
+
+	idx, err := result.StepIndex("Step 1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, idx)
+
+	view := result.Runs["1"].FirstView
+	step, ok := view.Step("Login")
+	assert.True(t, ok)
+	assert.Equal(t, "Login", step.EventName)
+
+	_, err = result.StepIndex("Checkout")
+	assert.True(t, errors.Is(err, ErrStepNotFound))
+	_, err = result.GetMedianRunByName("Checkout", "")
+	assert.True(t, errors.Is(err, ErrStepNotFound))
+
+	byName, err := result.GetMedianRunByName("Step 1", "loadTime")
+	assert.Nil(t, err)
+	byIndex, err := result.GetMedianRun(1, "loadTime")
+	assert.Nil(t, err)
+	assert.Equal(t, byIndex.FirstView.Run, byName.FirstView.Run)
+
+	summary, err := result.GetSummaryByName("Step 1", "loadTime")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.Step)
+}
+
+func TestCompareByEventName(t *testing.T) {
+	baseline := loadResult(t, "TestResultPlrAsNumber.json")
+	candidate := loadResult(t, "TestResultPlrAsNumber.json")
+	insertStep(candidate, "Login")
+
+	comparison, err := Compare(baseline, candidate, CompareOptions{
+		Metrics:     []string{"loadTime"},
+		ByEventName: true,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, comparison.Steps, 1)
+	assert.Equal(t, 0, comparison.Steps[0].Step)
+	assert.Equal(t, 1, comparison.Steps[0].CandidateStep)
+	assert.Equal(t, "Step 1", comparison.Steps[0].EventName)
+
+	// Step only in candidate is not compared
+	comparison, err = Compare(candidate, baseline, CompareOptions{ByEventName: true})
+	assert.Nil(t, err)
+	assert.Len(t, comparison.Steps, 1)
+	assert.Equal(t, 1, comparison.Steps[0].Step)
+	assert.Equal(t, 0, comparison.Steps[0].CandidateStep)
+}
@@ -0,0 +1,109 @@
+package webpagetest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Stats are aggregates of metric values across runs
+type Stats struct {
+	Count  int
+	Mean   float64
+	Median float64
+	// Sample standard deviation
+	StdDev float64
+	Min    float64
+	Max    float64
+	// Coefficient of variation (StdDev / Mean), high values mean noisy test
+	CV float64
+
+	// Values sorted in ascending order
+	Values []float64
+}
+
+// NewStats will compute stats of given values
+func NewStats(values []float64) Stats {
+	stats := Stats{Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	stats.Values = append([]float64(nil), values...)
+	sort.Float64s(stats.Values)
+	stats.Min = stats.Values[0]
+	stats.Max = stats.Values[len(stats.Values)-1]
+	stats.Median = stats.Percentile(50)
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	stats.Mean = sum / float64(len(values))
+
+	if len(values) > 1 {
+		var squares float64
+		for _, value := range values {
+			squares += (value - stats.Mean) * (value - stats.Mean)
+		}
+		stats.StdDev = math.Sqrt(squares / float64(len(values)-1))
+	}
+	if stats.Mean != 0 {
+		stats.CV = stats.StdDev / stats.Mean
+	}
+	return stats
+}
+
+// Percentile will return p-th percentile (0-100) of values, interpolated
+// linearly between closest ranks
+func (s Stats) Percentile(p float64) float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return s.Values[0]
+	}
+	if p >= 100 {
+		return s.Values[len(s.Values)-1]
+	}
+
+	rank := p / 100 * float64(len(s.Values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return s.Values[lower] + (s.Values[upper]-s.Values[lower])*(rank-float64(lower))
+}
+
+// MetricSummary is summary of metric for first and repeat views of one step
+type MetricSummary struct {
+	Metric string
+	// 0-based index of step
+	Step int
+
+	FirstView Stats
+	// RepeatView.Count is 0 if there are no successful repeat views
+	RepeatView Stats
+}
+
+// GetSummary will compute summary of metric for given step (0-based) across
+// successful runs. Median here is median of values, not value of median run
+func (rd *ResultData) GetSummary(step int, metric string) (*MetricSummary, error) {
+	firstView := rd.viewValues(step, metric, false)
+	if len(firstView) == 0 {
+		return nil, fmt.Errorf("no successful runs with metric %q for step %d", metric, step)
+	}
+
+	return &MetricSummary{
+		Metric:     metric,
+		Step:       step,
+		FirstView:  NewStats(runValues(firstView)),
+		RepeatView: NewStats(runValues(rd.viewValues(step, metric, true))),
+	}, nil
+}
+
+func runValues(values []runValue) []float64 {
+	result := make([]float64, 0, len(values))
+	for _, value := range values {
+		result = append(result, value.value)
+	}
+	return result
+}
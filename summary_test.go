@@ -0,0 +1,45 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStats(t *testing.T) {
+	stats := NewStats([]float64{4, 1, 3, 2})
+	assert.Equal(t, 4, stats.Count)
+	assert.Equal(t, 2.5, stats.Mean)
+	assert.Equal(t, 2.5, stats.Median)
+	assert.Equal(t, 1.0, stats.Min)
+	assert.Equal(t, 4.0, stats.Max)
+	assert.InDelta(t, 1.291, stats.StdDev, 0.001)
+	assert.InDelta(t, 0.516, stats.CV, 0.001)
+	assert.Equal(t, 1.3, stats.Percentile(10))
+	assert.Equal(t, 4.0, stats.Percentile(100))
+	assert.Equal(t, []float64{1, 2, 3, 4}, stats.Values)
+
+	single := NewStats([]float64{5})
+	assert.Equal(t, 0.0, single.StdDev)
+	assert.Equal(t, 5.0, single.Percentile(95))
+
+	assert.Equal(t, 0, NewStats(nil).Count)
+}
+
+func TestResultSummary(t *testing.T) {
+	result := loadResult(t, "TestResultPlrAsNumber.json")
+	setStep(result, "1", false, func(step *TestStep) { step.Result = 404 })
+
+	summary, err := result.GetSummary(0, "loadTime")
+	assert.Nil(t, err)
+	// 42602, 42743, 42755, 43102 without failed run 1
+	assert.Equal(t, 4, summary.FirstView.Count)
+	assert.Equal(t, 42749.0, summary.FirstView.Median)
+	assert.Equal(t, 42800.5, summary.FirstView.Mean)
+	assert.Equal(t, 42602.0, summary.FirstView.Min)
+	assert.Equal(t, 5, summary.RepeatView.Count)
+	assert.Equal(t, 12089.0, summary.RepeatView.Max)
+
+	_, err = result.GetSummary(0, "missing")
+	assert.NotNil(t, err)
+}
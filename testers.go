@@ -1,8 +1,9 @@
 package webpagetest
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 )
@@ -197,7 +198,12 @@ type Testers map[string][]Tester
 
 // GetTesters will retrieve all available agents and their status
 func (w *WebPageTest) GetTesters() (*Testers, error) {
-	body, err := w.query("/getTesters.php", url.Values{"f": []string{"json"}})
+	return w.GetTestersContext(context.Background())
+}
+
+// GetTestersContext is like GetTesters but uses ctx for the underlying request
+func (w *WebPageTest) GetTestersContext(ctx context.Context) (*Testers, error) {
+	body, err := w.query(ctx, "/getTesters.php", url.Values{"f": []string{"json"}})
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +214,12 @@ func (w *WebPageTest) GetTesters() (*Testers, error) {
 	}
 
 	if testers.StatusCode != 200 {
-		return nil, fmt.Errorf("Status != 200: %v", testers.StatusText)
+		return nil, &APIError{
+			Endpoint:   "/getTesters.php",
+			HTTPStatus: http.StatusOK,
+			StatusCode: testers.StatusCode,
+			StatusText: testers.StatusText,
+		}
 	}
 
 	result := make(Testers, 0)
@@ -0,0 +1,41 @@
+package webpagetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRoutes maps endpoint of WebPageTest API (like "/testStatus.php") to its handler
+type testRoutes map[string]http.HandlerFunc
+
+// newTestServer starts server, that answers to routes and responds with
+// 404 to everything else. Server is closed when test ends
+func newTestServer(t *testing.T, routes testRoutes) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestClient starts server with newTestServer and returns client for it
+func newTestClient(t *testing.T, routes testRoutes, options ...Option) *WebPageTest {
+	wpt, err := NewClient(newTestServer(t, routes).URL, options...)
+	assert.Nil(t, err)
+	return wpt
+}
+
+// respond returns handler, that writes body as is
+func respond(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}
+}
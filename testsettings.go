@@ -5,113 +5,123 @@ import (
 	"net/url"
 )
 
-// TestSettings is structure for describing what should be done in test run
+// TestSettings is structure for describing what should be done in test run.
+// Zero numbers mean server default (like 1 run or default viewport of
+// location) and are not sent to server, use Validate to catch invalid values
 type TestSettings struct {
 	// URL to be tested
-	URL string `json:",omitempty"`
+	URL string `json:",omitempty" yaml:"url,omitempty"`
 	// Label for the test
-	Label string `json:",omitempty"`
+	Label string `json:",omitempty" yaml:"label,omitempty"`
 
-	Where    string `json:",omitempty"`
-	Browser  string `json:",omitempty"`
-	Location string `json:",omitempty"`
+	Where    string `json:",omitempty" yaml:"where,omitempty"`
+	Browser  string `json:",omitempty" yaml:"browser,omitempty"`
+	Location string `json:",omitempty" yaml:"location,omitempty"`
 
 	// Viewport Width in css pixels
-	ScreenWidth int `json:",omitempty"`
+	ScreenWidth int `json:",omitempty" yaml:"screenWidth,omitempty"`
 	// Viewport Height in css pixels
-	ScreenHeight int `json:",omitempty"`
+	ScreenHeight int `json:",omitempty" yaml:"screenHeight,omitempty"`
 	// Default metric to use when calculating the median run (loadTime)
-	MedianMetric string `json:",omitempty"`
+	MedianMetric string `json:",omitempty" yaml:"medianMetric,omitempty"`
 	// Number of test runs (1-10 on the public instance) (1)
-	Runs int `json:",omitempty"`
+	Runs int `json:",omitempty" yaml:"runs,omitempty"`
 	// Scripted test to execute ("")
-	Script string `json:",omitempty"`
+	Script string `json:",omitempty" yaml:"script,omitempty"`
 	// Custom Headers
-	CustomHeaders string `json:",omitempty"`
+	CustomHeaders string `json:",omitempty" yaml:"customHeaders,omitempty"`
 	// Set to 1 to have Chrome capture the Dev Tools timeline (0)
-	Timeline bool `json:",omitempty"`
+	Timeline bool `json:",omitempty" yaml:"timeline,omitempty"`
 	// Set to 1 to skip the Repeat View test (0)
-	FirstViewOnly bool `json:",omitempty"`
+	FirstViewOnly bool `json:",omitempty" yaml:"firstViewOnly,omitempty"`
 	// Set to 1 to keep the test hidden from the test log (0)
-	Private bool `json:",omitempty"`
+	Private bool `json:",omitempty" yaml:"private,omitempty"`
 	// Set to 1 to capture video (video is required for calculating Speed Index) (0)
-	CaptureVideo bool `json:",omitempty"`
+	CaptureVideo bool `json:",omitempty" yaml:"captureVideo,omitempty"`
 	// Set to 1 to save a full-resolution version of the fully loaded screen shot as a png (0)
-	PNGScreenShot bool `json:",omitempty"`
+	PNGScreenShot bool `json:",omitempty" yaml:"pngScreenShot,omitempty"`
 	// Specify a jpeg compression level (30-100) for the screen shots and video capture (75)
-	ImageQuality int `json:",omitempty"`
+	ImageQuality int `json:",omitempty" yaml:"imageQuality,omitempty"`
 	// (optional) URL to ping when the test is complete (the test ID will be passed as an "id" parameter)
-	Pingback string `json:",omitempty"`
+	Pingback string `json:",omitempty" yaml:"pingback,omitempty"`
 	// (DOM) Element to record for sub-measurement
-	DOMElement string `json:",omitempty"`
+	DOMElement string `json:",omitempty" yaml:"domElement,omitempty"`
 	// (Override) the number of concurrent connections IE uses (0 to not override)	0
-	Connections int `json:",omitempty"`
+	Connections int `json:",omitempty" yaml:"connections,omitempty"`
 	// (optional) Set to between 1 - 5 to have Chrome include the Javascript call stack. Must be used in conjunction with "timeline". 	 0
-	TimelineStack int `json:",omitempty"`
+	TimelineStack int `json:",omitempty" yaml:"timelineStack,omitempty"`
 	// (optional) Set to 1 to force the test to stop at Document Complete (onLoad)	0
-	Web10 bool `json:",omitempty"`
+	Web10 bool `json:",omitempty" yaml:"web10,omitempty"`
 	// (optional) space-delimited list of urls to block
-	Block string `json:",omitempty"`
+	Block string `json:",omitempty" yaml:"block,omitempty"`
 	// (optional) User name to use for authenticated tests (http authentication)
-	Login string `json:",omitempty"`
+	Login string `json:",omitempty" yaml:"login,omitempty"`
 	// (optional) Password to use for authenticated tests (http authentication)
-	Password string `json:",omitempty"`
+	Password string `json:",omitempty" yaml:"password,omitempty"`
 	// (optional) Type of authentication to use: 0 = Basic Auth, 1 = SNS	0
-	AuthType string `json:",omitempty"`
+	AuthType string `json:",omitempty" yaml:"authType,omitempty"`
 	// (optional) e-mail address to notify with the test results
-	Notify string `json:",omitempty"`
+	Notify string `json:",omitempty" yaml:"notify,omitempty"`
 	// (optional) Download bandwidth in Kbps (used when specifying a custom connectivity profile)
-	BWDown int `json:",omitempty"`
+	BWDown int `json:",omitempty" yaml:"bwDown,omitempty"`
 	// (optional) Upload bandwidth in Kbps (used when specifying a custom connectivity profile)
-	BWUp int `json:",omitempty"`
+	BWUp int `json:",omitempty" yaml:"bwUp,omitempty"`
 	// (optional) First-hop Round Trip Time in ms (used when specifying a custom connectivity profile)
-	Latency int `json:",omitempty"`
+	Latency int `json:",omitempty" yaml:"latency,omitempty"`
 	// (optional) Packet loss rate - percent of packets to drop (used when specifying a custom connectivity profile)
-	PacketLossRate int `json:",omitempty"`
+	PacketLossRate int `json:",omitempty" yaml:"packetLossRate,omitempty"`
 	// (optional) (required for public instance)	API Key (if assigned) - applies only to runtest.php calls. Contact the site owner for a key if required (http://www.webpagetest.org/getkey.php for the public instance)
-	APIKey string `json:",omitempty"`
+	APIKey string `json:",omitempty" yaml:"apiKey,omitempty"`
 	// (optional) Set to 1 to enable tcpdump capture	 0
-	TCPDump bool `json:",omitempty"`
+	TCPDump bool `json:",omitempty" yaml:"tcpDump,omitempty"`
 	// (optional) Set to 1 to disable optimization checks (for faster testing)	0
-	NoOpt bool `json:",omitempty"`
+	NoOpt bool `json:",omitempty" yaml:"noOpt,omitempty"`
 	// (optional) Set to 1 to disable screen shot capturing	0
-	NoImages bool `json:",omitempty"`
+	NoImages bool `json:",omitempty" yaml:"noImages,omitempty"`
 	// (optional) Set to 1 to disable saving of the http headers (as well as browser status messages and CPU utilization)	0
-	NoHeaders bool `json:",omitempty"`
+	NoHeaders bool `json:",omitempty" yaml:"noHeaders,omitempty"`
 	// (optional) Set to 1 to disable javascript (IE, Chrome, Firefox)
-	NoScript bool `json:",omitempty"`
+	NoScript bool `json:",omitempty" yaml:"noScript,omitempty"`
 	// (optional) Set to 1 to clear the OS certificate caches (causes IE to do OCSP/CRL checks during SSL negotiation if the certificates are not already cached). Added in 2.11	 0
-	ClearCerts bool `json:",omitempty"`
+	ClearCerts bool `json:",omitempty" yaml:"clearCerts,omitempty"`
 	// (optional) Set to 1 to have Chrome emulate a mobile browser (screen resolution, UA string, fixed viewport).  Added in 2.11	 0
-	Mobile bool `json:",omitempty"`
+	Mobile bool `json:",omitempty" yaml:"mobile,omitempty"`
 	// (optional) Set to 1 to preserve the original browser User Agent string (don't append PTST to it)
-	KeepUA bool `json:",omitempty"`
+	KeepUA bool `json:",omitempty" yaml:"keepUA,omitempty"`
 	// (optional) Custom User Agent String to use
-	UAString string `json:",omitempty"`
+	UAString string `json:",omitempty" yaml:"uaString,omitempty"`
 	// (optional) Device Pixel Ratio to use when emulating mobile
-	DPR int `json:",omitempty"`
+	DPR int `json:",omitempty" yaml:"dpr,omitempty"`
 	// (optional) Set to 1 when capturing video to only store the video from the median run.	 0
-	MedianRunVideo bool `json:",omitempty"`
+	MedianRunVideo bool `json:",omitempty" yaml:"medianRunVideo,omitempty"`
 	// (optional)  Custom command-line options (Chrome only)
-	CmdLine string `json:",omitempty"`
+	CmdLine string `json:",omitempty" yaml:"cmdLine,omitempty"`
 	// (optional) Set to 1 to save the content of the first response (base page) instead of all of the text responses (bodies=1)
-	HTMLBody bool `json:",omitempty"`
+	HTMLBody bool `json:",omitempty" yaml:"htmlBody,omitempty"`
 	// (optional)  Custom metrics to collect at the end of a test
-	CustomMetrics string `json:",omitempty"`
+	CustomMetrics string `json:",omitempty" yaml:"customMetrics,omitempty"`
 	// (optional) Specify a specific tester that the test should run on (must match the PC name in /getTesters.php).  If the tester is not available the job will never run.
-	Tester string `json:",omitempty"`
+	Tester string `json:",omitempty" yaml:"tester,omitempty"`
 	// (optional) Specify a string that will be used to hash the test to a specific test agent.  The tester will be picked by index among the available testers.  If the number of testers changes then the tests will be distributed to different machines but if the counts remain consistent then the same string will always run the tests on the same test machine.  This can be useful for controlling variability when comparing a given URL over time or different parameters against each other (using the URL as the hash string).
-	Affinity string `json:",omitempty"`
+	Affinity string `json:",omitempty" yaml:"affinity,omitempty"`
 	// (optional) Set to 1 to Ignore SSL Certificate Errors e.g. Name mismatch, Self-signed certificates, etc.	 0
-	IgnoreSSL bool `json:",omitempty"`
+	IgnoreSSL bool `json:",omitempty" yaml:"ignoreSSL,omitempty"`
 	// (optional)  Device name from mobile_devices.ini to use for mobile emulation (only when mobile=1 is specified to enable emulation and only for Chrome)
-	MobileDevice string `json:",omitempty"`
+	MobileDevice string `json:",omitempty" yaml:"mobileDevice,omitempty"`
 	// (optional)  String to append to the user agent string. This is in addition to the default PTST/ver string. If "keepua" is also specified it will still append. Allows for substitution with some test parameters:
 	// %TESTID% - Replaces with the test ID for the current test
 	// %RUN% - Replaces with the current run number
 	// %CACHED% - Replaces with 1 for repeat view tests and 0 for initial view
 	// %VERSION% - Replaces with the current wptdriver version number
-	AppendUA string `json:",omitempty"`
+	AppendUA string `json:",omitempty" yaml:"appendUA,omitempty"`
+	// (optional) Priority of test in queue, 0 is the highest and 9 is the lowest (0)
+	Priority int `json:",omitempty" yaml:"priority,omitempty"`
+	// (optional) Set to 1 to capture Chrome trace (about://tracing) (0)
+	Trace bool `json:",omitempty" yaml:"trace,omitempty"`
+	// (optional) Set to 1 to capture Chrome network log (0)
+	NetLog bool `json:",omitempty" yaml:"netLog,omitempty"`
+	// (optional) Set to 1 to disable compatibility view (IE only) (0)
+	Standards bool `json:",omitempty" yaml:"standards,omitempty"`
 }
 
 // GetFormParams returns settings that was set ready to be passed to POST
@@ -119,13 +129,10 @@ func (s TestSettings) GetFormParams() url.Values {
 	values := url.Values{
 		"f":            {"json"},
 		"url":          {s.URL},
-		"runs":         {fmt.Sprintf("%d", s.Runs)},
 		"label":        {s.Label},
 		"where":        {s.Where},
 		"browser":      {s.Browser},
 		"location":     {s.Location},
-		"width":        {fmt.Sprintf("%d", s.ScreenWidth)},
-		"height":       {fmt.Sprintf("%d", s.ScreenHeight)},
 		"medianMetric": {s.MedianMetric},
 		"script":       {s.Script},
 		"pingback":     {s.Pingback},
@@ -145,6 +152,16 @@ func (s TestSettings) GetFormParams() url.Values {
 		"appendua":     {s.AppendUA},
 	}
 
+	// Zero means server default, like 1 run, not "0 runs"
+	if s.Runs > 0 {
+		values.Add("runs", fmt.Sprintf("%d", s.Runs))
+	}
+	if s.ScreenWidth > 0 {
+		values.Add("width", fmt.Sprintf("%d", s.ScreenWidth))
+	}
+	if s.ScreenHeight > 0 {
+		values.Add("height", fmt.Sprintf("%d", s.ScreenHeight))
+	}
 	if s.CustomHeaders != "" {
 		values.Add("customHeaders", s.CustomHeaders)
 	}
@@ -225,6 +242,18 @@ func (s TestSettings) GetFormParams() url.Values {
 	if s.DPR > 0 {
 		values.Add("dpr", fmt.Sprintf("%d", s.DPR))
 	}
+	if s.Priority > 0 {
+		values.Add("priority", fmt.Sprintf("%d", s.Priority))
+	}
+	if s.Trace {
+		values.Add("trace", "1")
+	}
+	if s.NetLog {
+		values.Add("netlog", "1")
+	}
+	if s.Standards {
+		values.Add("standards", "1")
+	}
 
 	return values
 }
@@ -0,0 +1,35 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFormParams(t *testing.T) {
+	// Zero numbers are left to server defaults
+	params := TestSettings{URL: "https://example.com"}.GetFormParams()
+	assert.Equal(t, "https://example.com", params.Get("url"))
+	for _, name := range []string{"runs", "width", "height", "iq", "bwDown", "connections", "priority"} {
+		_, ok := params[name]
+		assert.False(t, ok, name)
+	}
+
+	params = TestSettings{
+		URL:           "https://example.com",
+		Runs:          3,
+		ScreenWidth:   1280,
+		ScreenHeight:  720,
+		FirstViewOnly: true,
+		ImageQuality:  90,
+		Location:      "Dulles:Chrome.Custom",
+		BWDown:        5000,
+	}.GetFormParams()
+	assert.Equal(t, "3", params.Get("runs"))
+	assert.Equal(t, "1280", params.Get("width"))
+	assert.Equal(t, "720", params.Get("height"))
+	assert.Equal(t, "1", params.Get("fvonly"))
+	assert.Equal(t, "90", params.Get("iq"))
+	assert.Equal(t, "5000", params.Get("bwDown"))
+	assert.Equal(t, "Dulles:Chrome.Custom", params.Get("location"))
+}
@@ -0,0 +1,176 @@
+package webpagetest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError describes one invalid field of TestSettings
+type ValidationError struct {
+	// Name of TestSettings field, like "ImageQuality"
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+// Error implements error interface
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s (%v): %s", e.Field, e.Value, e.Reason)
+}
+
+// ValidationErrors is error with all problems found in TestSettings
+type ValidationErrors []ValidationError
+
+// Error implements error interface
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return "webpagetest: invalid test settings: " + strings.Join(messages, "; ")
+}
+
+// Is allows to match ValidationErrors with ErrInvalidSettings
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrInvalidSettings
+}
+
+// Validate will check settings for values WebPageTest can not handle or would
+// silently ignore. It returns ValidationErrors with every problem found, or nil
+func (s TestSettings) Validate() error {
+	var errs ValidationErrors
+	add := func(field string, value interface{}, reason string) {
+		errs = append(errs, ValidationError{Field: field, Value: value, Reason: reason})
+	}
+
+	if s.URL == "" && s.Script == "" {
+		add("URL", s.URL, "URL or Script is required")
+	}
+
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"Runs", s.Runs},
+		{"ScreenWidth", s.ScreenWidth},
+		{"ScreenHeight", s.ScreenHeight},
+		{"Connections", s.Connections},
+		{"DPR", s.DPR},
+		{"BWDown", s.BWDown},
+		{"BWUp", s.BWUp},
+		{"Latency", s.Latency},
+	} {
+		if field.value < 0 {
+			add(field.name, field.value, "must not be negative")
+		}
+	}
+
+	if s.ImageQuality != 0 && (s.ImageQuality < 30 || s.ImageQuality > 100) {
+		add("ImageQuality", s.ImageQuality, "must be between 30 and 100")
+	}
+	if s.PacketLossRate < 0 || s.PacketLossRate > 100 {
+		add("PacketLossRate", s.PacketLossRate, "must be between 0 and 100")
+	}
+
+	if s.TimelineStack < 0 || s.TimelineStack > 5 {
+		add("TimelineStack", s.TimelineStack, "must be between 1 and 5")
+	} else if s.TimelineStack > 0 && !s.Timeline {
+		add("TimelineStack", s.TimelineStack, "requires Timeline")
+	}
+
+	if s.MobileDevice != "" && !s.Mobile {
+		add("MobileDevice", s.MobileDevice, "requires Mobile")
+	}
+	if s.DPR > 0 && !s.Mobile {
+		add("DPR", s.DPR, "requires Mobile")
+	}
+
+	if s.Priority < 0 || s.Priority > 9 {
+		add("Priority", s.Priority, "must be between 0 and 9")
+	}
+
+	if s.AuthType != "" && s.AuthType != "0" && s.AuthType != "1" {
+		add("AuthType", s.AuthType, `must be "0" (Basic Auth) or "1" (SNS)`)
+	}
+
+	// Custom connectivity profile is defined by bandwidth, other parameters
+	// are ignored without it
+	_, _, connectivity := s.location()
+	if strings.EqualFold(connectivity, "Custom") && s.BWDown == 0 {
+		add("BWDown", s.BWDown, "required for custom connectivity")
+	} else if s.BWDown == 0 && (s.BWUp > 0 || s.Latency > 0 || s.PacketLossRate > 0) {
+		add("BWDown", s.BWDown, "required when BWUp, Latency or PacketLossRate is set")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ValidateWithLocations is like Validate, but also checks that location and
+// browser of settings are available on server, locations are taken from GetLocations
+func (s TestSettings) ValidateWithLocations(locations Locations) error {
+	var errs ValidationErrors
+	if err := s.Validate(); err != nil {
+		errs = err.(ValidationErrors)
+	}
+
+	name, browser, _ := s.location()
+	if name != "" {
+		location, ok := locations.find(name)
+		switch {
+		case !ok:
+			errs = append(errs, ValidationError{Field: "Location", Value: name, Reason: "unknown location"})
+		case browser != "" && !location.hasBrowser(browser):
+			errs = append(errs, ValidationError{Field: "Browser", Value: browser,
+				Reason: fmt.Sprintf("not available in location %s", name)})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// location will split Location ("Dulles:Chrome.Cable") into its parts.
+// Where and Browser are used if Location has no such parts
+func (s TestSettings) location() (name, browser, connectivity string) {
+	name = s.Location
+	if name == "" {
+		name = s.Where
+	}
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		name, browser = name[:idx], name[idx+1:]
+		if idx := strings.LastIndex(browser, "."); idx >= 0 {
+			browser, connectivity = browser[:idx], browser[idx+1:]
+		}
+	} else if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name, connectivity = name[:idx], name[idx+1:]
+	}
+	if browser == "" {
+		browser = s.Browser
+	}
+	return name, browser, connectivity
+}
+
+func (l Locations) find(name string) (*Location, bool) {
+	for _, group := range l {
+		for idx := range group {
+			if group[idx].Location == name {
+				return &group[idx], true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (l *Location) hasBrowser(browser string) bool {
+	for _, name := range l.Browsers {
+		if strings.EqualFold(strings.TrimSpace(name), browser) {
+			return true
+		}
+	}
+	return false
+}
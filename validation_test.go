@@ -0,0 +1,72 @@
+package webpagetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings TestSettings
+		fields   []string
+	}{
+		{"defaults", TestSettings{URL: "https://example.com"}, nil},
+		{"script only", TestSettings{Script: "navigate\thttps://example.com"}, nil},
+		{"no url", TestSettings{}, []string{"URL"}},
+		{"negative runs", TestSettings{URL: "https://example.com", Runs: -1, ScreenWidth: -10}, []string{"Runs", "ScreenWidth"}},
+		{"image quality", TestSettings{URL: "https://example.com", ImageQuality: 10}, []string{"ImageQuality"}},
+		{"timeline stack", TestSettings{URL: "https://example.com", TimelineStack: 3}, []string{"TimelineStack"}},
+		{"timeline stack with timeline", TestSettings{URL: "https://example.com", TimelineStack: 3, Timeline: true}, nil},
+		{"mobile device", TestSettings{URL: "https://example.com", MobileDevice: "Pixel2", DPR: 2}, []string{"MobileDevice", "DPR"}},
+		{"custom connectivity", TestSettings{URL: "https://example.com", Location: "Dulles:Chrome.Custom", Latency: 40}, []string{"BWDown"}},
+		{"bandwidth parameters without BWDown", TestSettings{URL: "https://example.com", BWUp: 1000}, []string{"BWDown"}},
+		{"custom connectivity with bandwidth", TestSettings{URL: "https://example.com", Location: "Dulles:Chrome.Custom", BWDown: 5000}, nil},
+		{"auth type", TestSettings{URL: "https://example.com", AuthType: "basic"}, []string{"AuthType"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.settings.Validate()
+			if test.fields == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrInvalidSettings))
+
+			var errs ValidationErrors
+			assert.True(t, errors.As(err, &errs))
+			fields := make([]string, 0, len(errs))
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, test.fields, fields)
+		})
+	}
+}
+
+func TestValidateWithLocations(t *testing.T) {
+	locations := Locations{"Desktop": {
+		{Location: "Dulles", Browsers: []string{"Chrome", "Firefox"}},
+	}}
+
+	assert.Nil(t, TestSettings{URL: "https://example.com", Location: "Dulles:Chrome.Cable"}.ValidateWithLocations(locations))
+	assert.Nil(t, TestSettings{URL: "https://example.com", Location: "Dulles", Browser: "firefox"}.ValidateWithLocations(locations))
+
+	err := TestSettings{URL: "https://example.com", Location: "Dulles:Safari"}.ValidateWithLocations(locations)
+	assert.EqualError(t, err, "webpagetest: invalid test settings: Browser (Safari): not available in location Dulles")
+
+	err = TestSettings{Location: "London:Chrome"}.ValidateWithLocations(locations)
+	assert.Len(t, err, 2)
+	assert.Equal(t, "Location", err.(ValidationErrors)[1].Field)
+}
+
+func TestRunTestValidatesSettings(t *testing.T) {
+	wpt, err := NewClient("http://127.0.0.1:1")
+	assert.Nil(t, err)
+
+	_, err = wpt.RunTest(TestSettings{URL: "https://example.com", ImageQuality: 101})
+	assert.True(t, errors.Is(err, ErrInvalidSettings))
+}
@@ -0,0 +1,188 @@
+package webpagetest
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitOptions controls how client waits for test to complete
+type WaitOptions struct {
+	// Interval between first status polls (10s)
+	PollInterval time.Duration
+	// Upper limit for interval between polls when Backoff is used (PollInterval)
+	MaxPollInterval time.Duration
+	// Multiplier for poll interval after every poll, values <= 1 mean constant interval
+	Backoff float64
+	// Overall time limit for waiting, 0 means no limit besides ctx
+	Timeout time.Duration
+	// Try to cancel test on server if Timeout is reached
+	CancelOnTimeout bool
+	// Number of consecutive status polls, that may fail with network or server
+	// error before waiting is aborted (3), negative means that any error aborts
+	MaxPollErrors int
+	// Pingback receiver to learn about test completion without waiting for
+	// next poll, polling is still used as a fallback
+	Pingback *PingbackReceiver
+
+	// OnStatus is called for every status poll, in order, before next poll
+	OnStatus func(StatusEvent)
+	// Events receives every status poll, in order. Sends are blocking, so it
+	// must be read or buffered. Channel is not closed when waiting is over
+	Events chan<- StatusEvent
+}
+
+// StatusEvent is progress update for test that we wait for
+type StatusEvent struct {
+	TestID     string
+	StatusCode int
+	StatusText string
+
+	// Number of tests ahead of this one in the queue
+	BehindCount int
+	// Completed and expected number of test runs
+	RunsCompleted int
+	RunsExpected  int
+	// Time since we started to wait
+	Elapsed time.Duration
+
+	// Status as it was returned by GetTestStatus
+	Status *TestStatus
+}
+
+func newStatusEvent(testID string, status *TestStatus, started time.Time) StatusEvent {
+	return StatusEvent{
+		TestID:        testID,
+		StatusCode:    status.StatusCode,
+		StatusText:    status.StatusText,
+		BehindCount:   status.BehindCount,
+		RunsCompleted: status.TestsCompleted,
+		RunsExpected:  status.TestsExpected,
+		Elapsed:       time.Since(started),
+		Status:        status,
+	}
+}
+
+// IsComplete tells if test is not running anymore (completed or failed)
+func (e StatusEvent) IsComplete() bool {
+	return e.StatusCode >= 200
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.PollInterval <= 0 {
+		o.PollInterval = 10 * time.Second
+	}
+	if o.MaxPollInterval < o.PollInterval {
+		o.MaxPollInterval = o.PollInterval
+	}
+	if o.Backoff < 1 {
+		o.Backoff = 1
+	}
+	if o.MaxPollErrors == 0 {
+		o.MaxPollErrors = 3
+	}
+	return o
+}
+
+// RunTestAndWaitWithOptions will start new test and wait for it to complete as described by opts
+func (w *WebPageTest) RunTestAndWaitWithOptions(ctx context.Context, settings TestSettings, opts WaitOptions) (*ResultData, error) {
+	if opts.Pingback != nil && settings.Pingback == "" {
+		settings.Pingback = opts.Pingback.URL
+	}
+	test, err := w.RunTestContext(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.WaitForTest(ctx, test.TestID, opts)
+}
+
+// WaitForTest will poll status of already started test until it is completed and
+// then will return its results
+func (w *WebPageTest) WaitForTest(ctx context.Context, testID string, opts WaitOptions) (*ResultData, error) {
+	if err := w.waitForStatus(ctx, testID, opts); err != nil {
+		return nil, err
+	}
+
+	return w.GetTestResultContext(ctx, testID)
+}
+
+// waitForStatus blocks until test is completed according to testStatus.php
+func (w *WebPageTest) waitForStatus(ctx context.Context, testID string, opts WaitOptions) error {
+	opts = opts.withDefaults()
+	started := time.Now()
+
+	waitCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var pingback <-chan struct{}
+	if opts.Pingback != nil {
+		pingback = opts.Pingback.wait(testID)
+		defer opts.Pingback.forget(testID)
+	}
+
+	interval := opts.PollInterval
+	var pollErrors int
+	for {
+		status, err := w.GetTestStatusContext(waitCtx, testID)
+		switch {
+		case err == nil:
+			pollErrors = 0
+			event := newStatusEvent(testID, status, started)
+			if opts.OnStatus != nil {
+				opts.OnStatus(event)
+			}
+			if opts.Events != nil {
+				select {
+				case opts.Events <- event:
+				case <-waitCtx.Done():
+					return w.waitError(ctx, waitCtx, testID, opts, waitCtx.Err())
+				}
+			}
+			if event.IsComplete() {
+				return nil
+			}
+		case waitCtx.Err() == nil && isTransient(err) && pollErrors < opts.MaxPollErrors:
+			// Test is still running on server, try again on next poll
+			pollErrors++
+		default:
+			return w.waitError(ctx, waitCtx, testID, opts, err)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			return w.waitError(ctx, waitCtx, testID, opts, waitCtx.Err())
+		case <-timer.C:
+		case <-pingback:
+			// Test is most likely completed, check it right away
+			timer.Stop()
+		}
+
+		interval = time.Duration(float64(interval) * opts.Backoff)
+		if interval > opts.MaxPollInterval {
+			interval = opts.MaxPollInterval
+		}
+	}
+}
+
+// waitError will turn error caused by our own Timeout into ErrWaitTimeout and
+// will cancel test, if it was asked for
+func (w *WebPageTest) waitError(ctx, waitCtx context.Context, testID string, opts WaitOptions, err error) error {
+	if ctx.Err() != nil || waitCtx.Err() != context.DeadlineExceeded {
+		return err
+	}
+
+	if opts.CancelOnTimeout {
+		cancelCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		// Test may be already running and can't be cancelled, there's nothing we can do about it
+		w.CancelTestContext(cancelCtx, testID)
+	}
+	return fmt.Errorf("test %s is not completed after %v: %w", testID, opts.Timeout, ErrWaitTimeout)
+}
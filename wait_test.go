@@ -0,0 +1,64 @@
+package webpagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForTest(t *testing.T) {
+	result, err := ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+
+	var polls, cancels int32
+	wpt := newTestClient(t, testRoutes{
+		"/testStatus.php": func(w http.ResponseWriter, r *http.Request) {
+			poll := atomic.AddInt32(&polls, 1)
+			code, text := 100, "Test Started"
+			if poll == 1 {
+				code, text = 101, "Waiting behind 2 other tests..."
+			}
+			if poll >= 3 && r.URL.Query().Get("test") == "done" {
+				code, text = 200, "Test Complete"
+			}
+			fmt.Fprintf(w, `{"statusCode": %d, "statusText": %q, "data": {"statusCode": %d, "statusText": %q,
+				"behindCount": %d, "testsExpected": 3, "testsCompleted": %d}}`, code, text, code, text, 3-poll, poll-1)
+		},
+		"/jsonResult.php": respond(string(result)),
+		"/cancelTest.php": func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&cancels, 1)
+			w.Write([]byte(`<h3 align="center">Test cancelled!</h3>`))
+		},
+	})
+
+	var events []StatusEvent
+	data, err := wpt.WaitForTest(context.Background(), "done", WaitOptions{
+		PollInterval:    time.Millisecond,
+		MaxPollInterval: 3 * time.Millisecond,
+		Backoff:         2,
+		OnStatus:        func(event StatusEvent) { events = append(events, event) },
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+	assert.Len(t, events, 3)
+	assert.Equal(t, 101, events[0].StatusCode)
+	assert.Equal(t, 2, events[0].BehindCount)
+	assert.Equal(t, 1, events[1].RunsCompleted)
+	assert.True(t, events[2].IsComplete())
+
+	atomic.StoreInt32(&polls, 0)
+	_, err = wpt.WaitForTest(context.Background(), "stuck", WaitOptions{
+		PollInterval:    time.Millisecond,
+		Timeout:         20 * time.Millisecond,
+		CancelOnTimeout: true,
+	})
+	assert.True(t, errors.Is(err, ErrWaitTimeout))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cancels))
+}
@@ -2,34 +2,63 @@ package webpagetest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"html"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"time"
+	"strings"
 )
 
+// WebPageTest is a client for WebPageTest server API
 type WebPageTest struct {
 	Host string
+
+	client    *http.Client
+	transport http.RoundTripper
+	userAgent string
+	apiKey    string
+	headers   http.Header
+	retry     RetryPolicy
+	limiter   *RateLimiter
 }
 
-func NewClient(host string) (*WebPageTest, error) {
+// NewClient will create new client for WebPageTest server at given host.
+// By default http.DefaultClient is used for requests, it can be changed with options
+func NewClient(host string, options ...Option) (*WebPageTest, error) {
 	validURL, err := url.Parse(host)
 	if err != nil {
 		return nil, err
 	}
 
-	return &WebPageTest{
-		Host: validURL.String(),
-	}, nil
+	w := &WebPageTest{
+		Host:    validURL.String(),
+		client:  http.DefaultClient,
+		headers: make(http.Header),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if w.transport != nil {
+		client := *w.client
+		client.Transport = w.transport
+		w.client = &client
+	}
+
+	return w, nil
 }
 
 // CancelTest will try to cancel test by it's ID
 // With a test ID (and if required, API key) you can cancel a test if it has not started running.
 func (w *WebPageTest) CancelTest(testID string) error {
+	return w.CancelTestContext(context.Background(), testID)
+}
+
+// CancelTestContext is like CancelTest but uses ctx for the underlying request
+func (w *WebPageTest) CancelTestContext(ctx context.Context, testID string) error {
 	// http://www.webpagetest.org/cancelTest.php?test=<testId>&k=<API key>
-	body, err := w.query("/cancelTest.php", url.Values{"test": []string{testID}})
+	body, err := w.query(ctx, "/cancelTest.php", url.Values{"test": []string{testID}})
 	if err != nil {
 		return err
 	}
@@ -38,35 +67,66 @@ func (w *WebPageTest) CancelTest(testID string) error {
 	// <h3 align="center">Test cancelled!</h3><form><i
 	if bytes.Contains(body, []byte("Sorry, the test could not be cancelled.")) {
 		// Trim left <h3> and split by < to get beginning of message
-		return fmt.Errorf("%s", string(bytes.SplitN(bytes.TrimLeft(body, "<h3>"), []byte("<"), 2)[0]))
+		return &APIError{
+			Endpoint:   "/cancelTest.php",
+			TestID:     testID,
+			HTTPStatus: http.StatusOK,
+			StatusText: string(bytes.SplitN(bytes.TrimLeft(body, "<h3>"), []byte("<"), 2)[0]),
+		}
 	}
 	if bytes.Contains(body, []byte("Test cancelled!")) {
 		return nil
 	}
 
-	return fmt.Errorf("Unknown error: %s", string(body))
+	return &APIError{
+		Endpoint:   "/cancelTest.php",
+		TestID:     testID,
+		HTTPStatus: http.StatusOK,
+		StatusText: "unexpected response: " + truncate(string(body), 200),
+	}
 }
 
-func (w *WebPageTest) query(api string, params url.Values) ([]byte, error) {
+func (w *WebPageTest) query(ctx context.Context, api string, params url.Values) ([]byte, error) {
 	// http://www.webpagetest.org/cancelTest.php?test=<testId>&k=<API key>
+	if w.apiKey != "" && params.Get("k") == "" {
+		params.Set("k", w.apiKey)
+	}
 	queryUrl := w.Host + api + "?" + params.Encode()
-	resp, err := http.Get(queryUrl)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, queryUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GET \"%s\": %v", queryUrl, err)
+		return nil, &RequestError{Endpoint: api, Err: err}
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, &RequestError{Endpoint: api, Err: err}
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Status is no OK: %v [%v]", resp.StatusCode, string(body))
+		return nil, &APIError{
+			Endpoint:   api,
+			TestID:     params.Get("test"),
+			HTTPStatus: resp.StatusCode,
+			StatusText: truncate(string(body), 200),
+		}
 	}
 
 	return body, nil
 }
 
+// truncate will cut s to at most n bytes, so we don't put whole html pages in errors
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
 /*
 {
   "statusCode": 200,
@@ -83,40 +143,81 @@ func (w *WebPageTest) query(api string, params url.Values) ([]byte, error) {
 }
 */
 
-func (w *WebPageTest) RunTest(settings TestSettings) (string, error) {
-	resp, err := http.PostForm(w.Host+"/runtest.php", settings.GetFormParams())
-	if err != nil {
-		return "", err
+// RunTestResponse is what runtest.php returns for started test
+type RunTestResponse struct {
+	TestID string `json:"testId"`
+	// Owner key of the test, keep it if you want to manage test later
+	OwnerKey string `json:"ownerKey"`
+	// Links to test results in various formats
+	JSONURL    string `json:"jsonUrl"`
+	XMLURL     string `json:"xmlUrl"`
+	UserURL    string `json:"userUrl"`
+	SummaryCSV string `json:"summaryCSV"`
+	DetailCSV  string `json:"detailCSV"`
+}
+
+// RunTest will start new WebPageTest test run with given TestSettings.
+// Settings are checked with TestSettings.Validate before test is submitted
+func (w *WebPageTest) RunTest(settings TestSettings) (*RunTestResponse, error) {
+	return w.RunTestContext(context.Background(), settings)
+}
+
+// RunTestContext is like RunTest but uses ctx for the underlying request
+func (w *WebPageTest) RunTestContext(ctx context.Context, settings TestSettings) (*RunTestResponse, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", err
+	params := settings.GetFormParams()
+	if w.apiKey != "" && params.Get("k") == "" {
+		params.Set("k", w.apiKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Host+"/runtest.php",
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
+	resp, err := w.do(req)
+	if err != nil {
+		return nil, &RequestError{Endpoint: "/runtest.php", Err: err}
+	}
 	defer resp.Body.Close()
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, &RequestError{Endpoint: "/runtest.php", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{
+			Endpoint:   "/runtest.php",
+			HTTPStatus: resp.StatusCode,
+			StatusText: truncate(string(body), 200),
+		}
 	}
 
 	var result struct {
-		StatusCode int    `json:"statusCode"`
-		StatusText string `json:"statusText"`
-		Data       struct {
-			TestID  string `json:"testId"`
-			UserURL string `json:"userUrl"`
-		} `json:"data"`
+		StatusCode int             `json:"statusCode"`
+		StatusText string          `json:"statusText"`
+		Data       RunTestResponse `json:"data"`
 	}
 	if err = json.Unmarshal(body, &result); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if result.StatusCode > 200 {
-		return "", fmt.Errorf("StatusCode > 200: %v: %v", result.StatusCode, result.StatusText)
+		return nil, &APIError{
+			Endpoint:   "/runtest.php",
+			HTTPStatus: resp.StatusCode,
+			StatusCode: result.StatusCode,
+			StatusText: result.StatusText,
+		}
 	}
 
-	fmt.Printf("Result URL for %v: %v\n", settings.URL, result.Data.UserURL)
-	return result.Data.TestID, nil
+	// Server escapes "&" in some of the links
+	result.Data.DetailCSV = html.UnescapeString(result.Data.DetailCSV)
+	return &result.Data, nil
 }
 
 // StatusCallback is helper type for function to be called while waiting for test to complete
@@ -125,37 +226,21 @@ type StatusCallback func(testID, status string, duration int)
 // RunTestAndWait will start new WebPageTest test run with given TestSettings and will wait for it
 // to complete. While it wait, it will poll status updates from server and will call StatusCallback with it
 func (w *WebPageTest) RunTestAndWait(settings TestSettings, callback StatusCallback) (*ResultData, error) {
-	testID, err := w.RunTest(settings)
-
-	if err != nil {
-		return nil, err
-	}
+	return w.RunTestAndWaitContext(context.Background(), settings, callback)
+}
 
-	for {
-		result, err := w.GetTestStatus(testID)
-		if err != nil {
-			return nil, err
-		}
-		// Call callback
-		if callback != nil {
-			go callback(testID, result.StatusText, result.Elapsed)
-		}
-		if result.StatusCode < 200 {
-			time.Sleep(10 * time.Second)
-		}
-		if result.StatusCode >= 200 {
-			break
+// RunTestAndWaitContext is like RunTestAndWait but stops waiting as soon as ctx is done
+func (w *WebPageTest) RunTestAndWaitContext(ctx context.Context, settings TestSettings, callback StatusCallback) (*ResultData, error) {
+	var opts WaitOptions
+	if callback != nil {
+		opts.OnStatus = func(event StatusEvent) {
+			callback(event.TestID, event.StatusText, event.Status.Elapsed)
 		}
 	}
 
-	testResult, err := w.GetTestResult(testID)
-	if err != nil {
-		return nil, err
-	}
-	return testResult, nil
+	return w.RunTestAndWaitWithOptions(ctx, settings, opts)
 }
 
-// getHARData(id, options, callback)
 // getPageSpeedData(id, options, callback)
 // getUtilizationData(id, options, callback)
 // getRequestData(id, options, callback)
@@ -171,4 +256,3 @@ func (w *WebPageTest) RunTestAndWait(settings TestSettings, callback StatusCallb
 // getScreenshotImage(id, options, callback)
 // createVideo(tests, options, callback)
 // getEmbedVideoPlayer(id, options, callback)
-// scriptToString(script)
@@ -0,0 +1,38 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRunTestResponse is response of runtest.php from webpagetest.go
+const testRunTestResponse = `{
+  "statusCode": 200,
+  "statusText": "Ok",
+  "data": {
+    "testId": "161128_R3_2",
+    "ownerKey": "c9d1754ea6388229093c69adac3740e0339fa100",
+    "jsonUrl": "http://webpagetest.app.s/jsonResult.php?test=161128_R3_2",
+    "xmlUrl": "http://webpagetest.app.s/xmlResult.php?test=161128_R3_2",
+    "userUrl": "http://webpagetest.app.s/results.php?test=161128_R3_2",
+    "summaryCSV": "http://webpagetest.app.s/csv.php?test=161128_R3_2",
+    "detailCSV": "http://webpagetest.app.s/csv.php?test=161128_R3_2&amp;requests=1"
+  }
+}`
+
+func TestRunTestResponse(t *testing.T) {
+	wpt := newTestClient(t, testRoutes{"/runtest.php": respond(testRunTestResponse)})
+
+	test, err := wpt.RunTest(TestSettings{URL: "https://example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, &RunTestResponse{
+		TestID:     "161128_R3_2",
+		OwnerKey:   "c9d1754ea6388229093c69adac3740e0339fa100",
+		JSONURL:    "http://webpagetest.app.s/jsonResult.php?test=161128_R3_2",
+		XMLURL:     "http://webpagetest.app.s/xmlResult.php?test=161128_R3_2",
+		UserURL:    "http://webpagetest.app.s/results.php?test=161128_R3_2",
+		SummaryCSV: "http://webpagetest.app.s/csv.php?test=161128_R3_2",
+		DetailCSV:  "http://webpagetest.app.s/csv.php?test=161128_R3_2&requests=1",
+	}, test)
+}
@@ -0,0 +1,220 @@
+package wpttest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mode of Recorder
+type Mode int
+
+const (
+	// ModeReplay serves responses from fixtures and never goes to network
+	ModeReplay Mode = iota
+	// ModeRecord passes requests to real server and saves every request/response pair
+	ModeRecord
+)
+
+// Redacted is put instead of API keys and owner keys in fixtures
+const Redacted = "REDACTED"
+
+// Parameters and headers that carry secrets
+var (
+	secretParams  = []string{"k", "key", "ownerKey"}
+	secretHeaders = []string{"X-WPT-API-KEY", "Authorization", "Cookie", "Set-Cookie"}
+	ownerKeyRe    = regexp.MustCompile(`("ownerKey"\s*:\s*)"[^"]*"`)
+)
+
+// Fixture is one recorded request/response pair
+type Fixture struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Body   string `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int         `json:"status"`
+		Header     http.Header `json:"header"`
+		Body       string      `json:"body"`
+	} `json:"response"`
+}
+
+// Recorder is http.RoundTripper, that records responses of real WebPageTest
+// server to fixture directory and replays them back. Identical requests are
+// numbered, so sequence of status polls is replayed in the same order; when
+// recorded sequence is over, last response is repeated.
+//
+//	recorder := wpttest.NewRecorder("testdata/fixtures", wpttest.ModeReplay)
+//	wpt, _ := webpagetest.NewClient("https://www.webpagetest.org", webpagetest.WithTransport(recorder))
+type Recorder struct {
+	Dir  string
+	Mode Mode
+	// Transport for real requests in ModeRecord (http.DefaultTransport)
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	sequence map[string]int
+}
+
+// NewRecorder creates recorder that keeps fixtures in dir
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{
+		Dir:      dir,
+		Mode:     mode,
+		sequence: make(map[string]int),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	requestURL := scrubURL(req.URL)
+	requestBody := scrubBody(req.Header.Get("Content-Type"), body)
+	key := fixtureKey(req.Method, requestURL, requestBody)
+
+	r.mu.Lock()
+	n := r.sequence[key]
+	r.sequence[key]++
+	r.mu.Unlock()
+
+	if r.Mode == ModeRecord {
+		return r.record(req, key, n, requestURL, requestBody)
+	}
+	return r.replay(req, key, n)
+}
+
+func (r *Recorder) record(req *http.Request, key string, n int, requestURL, requestBody string) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var fixture Fixture
+	fixture.Request.Method = req.Method
+	fixture.Request.URL = requestURL
+	fixture.Request.Body = requestBody
+	fixture.Response.StatusCode = resp.StatusCode
+	fixture.Response.Header = scrubHeader(resp.Header)
+	fixture.Response.Body = ownerKeyRe.ReplaceAllString(string(body), `${1}"`+Redacted+`"`)
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(r.Dir, fixtureName(key, n)), data, 0644); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, key string, n int) (*http.Response, error) {
+	data, err := ioutil.ReadFile(filepath.Join(r.Dir, fixtureName(key, n)))
+	// Sequence is over, repeat last recorded response
+	for os.IsNotExist(err) && n > 0 {
+		n--
+		data, err = ioutil.ReadFile(filepath.Join(r.Dir, fixtureName(key, n)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s %s: %v", req.Method, req.URL.Path, err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %v", fixtureName(key, n), err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        fixture.Response.Header,
+		Body:          ioutil.NopCloser(strings.NewReader(fixture.Response.Body)),
+		ContentLength: int64(len(fixture.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// fixtureKey identifies request regardless of host and secrets
+func fixtureKey(method, requestURL, body string) string {
+	parsed, _ := url.Parse(requestURL)
+	hash := sha1.Sum([]byte(method + " " + parsed.RequestURI() + "\n" + body))
+	return strings.TrimSuffix(path.Base(parsed.Path), ".php") + "_" + hex.EncodeToString(hash[:])[:12]
+}
+
+func fixtureName(key string, n int) string {
+	return fmt.Sprintf("%s_%d.json", key, n)
+}
+
+// scrubURL returns URL with secret parameters redacted and sorted query
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	scrubbed.RawQuery = scrubValues(u.Query()).Encode()
+	return scrubbed.String()
+}
+
+// scrubBody redacts secrets in form encoded body
+func scrubBody(contentType string, body []byte) string {
+	if !strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return string(body)
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return string(body)
+	}
+	return scrubValues(values).Encode()
+}
+
+func scrubValues(values url.Values) url.Values {
+	for _, param := range secretParams {
+		if values.Get(param) != "" {
+			values.Set(param, Redacted)
+		}
+	}
+	return values
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	for _, key := range secretHeaders {
+		scrubbed.Del(key)
+	}
+	return scrubbed
+}
@@ -0,0 +1,65 @@
+package wpttest_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/olegfedoseev/go-webpagetest"
+	"github.com/olegfedoseev/go-webpagetest/wpttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	server := wpttest.NewServer()
+	server.APIKey = "secret"
+
+	recorder := wpttest.NewRecorder(dir, wpttest.ModeRecord)
+	wpt, err := webpagetest.NewClient(server.URL, webpagetest.WithAPIKey("secret"),
+		webpagetest.WithHeader("X-WPT-API-KEY", "secret"), webpagetest.WithTransport(recorder))
+	assert.Nil(t, err)
+
+	test, err := wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com", Runs: 2})
+	assert.Nil(t, err)
+	assert.NotEqual(t, wpttest.Redacted, test.OwnerKey)
+	status, err := wpt.GetTestStatus(test.TestID)
+	assert.Nil(t, err)
+	recorded, err := wpt.GetTestResult(test.TestID)
+	assert.Nil(t, err)
+	server.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Nil(t, err)
+	assert.Len(t, files, 3)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		assert.False(t, strings.Contains(string(data), "secret"), file)
+	}
+
+	// Server is gone, everything comes from fixtures
+	replayer := wpttest.NewRecorder(dir, wpttest.ModeReplay)
+	wpt, err = webpagetest.NewClient(server.URL, webpagetest.WithAPIKey("other"),
+		webpagetest.WithTransport(replayer))
+	assert.Nil(t, err)
+
+	replayedTest, err := wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com", Runs: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, test.TestID, replayedTest.TestID)
+	assert.Equal(t, wpttest.Redacted, replayedTest.OwnerKey)
+
+	// Last response is repeated when sequence is over
+	for i := 0; i < 2; i++ {
+		replayedStatus, err := wpt.GetTestStatus(test.TestID)
+		assert.Nil(t, err)
+		assert.Equal(t, status.StatusCode, replayedStatus.StatusCode)
+	}
+	replayed, err := wpt.GetTestResult(test.TestID)
+	assert.Nil(t, err)
+	assert.Equal(t, recorded, replayed)
+
+	_, err = wpt.GetLocations()
+	assert.NotNil(t, err)
+}
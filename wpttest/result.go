@@ -0,0 +1,111 @@
+package wpttest
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Variation of metrics between runs, so median and stats have something to work with
+var runOffsets = []int{0, 120, -80, 60, -40, 200, -150, 30, -10, 90}
+
+// generateResult builds "data" of jsonResult.php response for completed test
+func generateResult(test *Test, baseURL string) map[string]interface{} {
+	runs := testRuns(test)
+	firstViewOnly := test.Params["fvonly"] == "1"
+
+	results := make(map[string]interface{})
+	for run := 1; run <= runs; run++ {
+		views := map[string]interface{}{
+			"firstView": generateStep(test, run, false),
+		}
+		if !firstViewOnly {
+			views["repeatView"] = generateStep(test, run, true)
+		}
+		results[strconv.Itoa(run)] = views
+	}
+
+	successfulRVRuns := runs
+	if firstViewOnly {
+		successfulRVRuns = 0
+	}
+
+	return map[string]interface{}{
+		"id":               test.ID,
+		"url":              test.Params["url"],
+		"summary":          baseURL + "/results.php?test=" + test.ID,
+		"testUrl":          test.Params["url"],
+		"location":         test.Params["location"],
+		"label":            test.Params["label"],
+		"from":             "Test Location",
+		"connectivity":     "Cable",
+		"bwDown":           5000,
+		"bwUp":             1000,
+		"latency":          28,
+		"plr":              "0",
+		"mobile":           atoi(test.Params["mobile"], 0),
+		"completed":        time.Now().Unix(),
+		"tester":           "Test-1",
+		"testerDNS":        "127.0.0.1",
+		"fvonly":           firstViewOnly,
+		"successfulFVRuns": runs,
+		"successfulRVRuns": successfulRVRuns,
+		"runs":             results,
+		"testInfo": map[string]interface{}{
+			"url":          test.Params["url"],
+			"runs":         runs,
+			"fvonly":       atoi(test.Params["fvonly"], 0),
+			"label":        test.Params["label"],
+			"medianMetric": test.Params["medianMetric"],
+		},
+	}
+}
+
+// generateStep builds metrics of single test view
+func generateStep(test *Test, run int, cached bool) map[string]interface{} {
+	offset := runOffsets[(run-1)%len(runOffsets)]
+	scale := func(value int) int {
+		value += offset * value / 1000
+		if cached {
+			value = value * 4 / 10
+		}
+		return value
+	}
+	cachedFlag := 0
+	if cached {
+		cachedFlag = 1
+	}
+
+	return map[string]interface{}{
+		"numSteps":             1,
+		"step":                 1,
+		"run":                  run,
+		"cached":               cachedFlag,
+		"eventName":            "Step 1",
+		"URL":                  test.Params["url"],
+		"tester":               "Test-1",
+		"result":               0,
+		"date":                 test.Started.Unix(),
+		"TTFB":                 scale(300),
+		"render":               scale(900),
+		"firstContentfulPaint": scale(950),
+		"firstPaint":           float64(scale(880)),
+		"domInteractive":       scale(1200),
+		"loadTime":             scale(2000),
+		"docTime":              scale(2000),
+		"fullyLoaded":          scale(3000),
+		"visualComplete":       scale(2500),
+		"lastVisualChange":     scale(2500),
+		"SpeedIndex":           scale(1500),
+		"bytesIn":              scale(500000),
+		"bytesInDoc":           scale(400000),
+		"bytesOut":             scale(20000),
+		"requestsFull":         scale(50),
+		"requestsDoc":          scale(40),
+		"domElements":          500,
+		"connections":          10,
+		"pages": map[string]string{
+			"details": fmt.Sprintf("/details.php?test=%s&run=%d&cached=%d", test.ID, run, cachedFlag),
+		},
+	}
+}
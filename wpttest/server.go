@@ -0,0 +1,537 @@
+// Package wpttest provides fake WebPageTest server for offline testing of code,
+// that uses webpagetest client.
+//
+// Server is built on top of httptest.Server and implements runtest.php,
+// testStatus.php, jsonResult.php, cancelTest.php, getLocations.php and
+// getTesters.php. Every started test goes through states queued -> running ->
+// complete (or failed), how long it stays in every state and how it ends is
+// controlled by Behavior:
+//
+//	server := wpttest.NewServer()
+//	defer server.Close()
+//
+//	server.SetBehavior(wpttest.Behavior{QueuedFor: time.Second, RunningFor: 2 * time.Second})
+//	wpt, _ := webpagetest.NewClient(server.URL)
+//
+// Recorder can be used to record responses of real server once and replay
+// them in tests later.
+package wpttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is state of test on fake server
+type State int
+
+// States of test, as it goes through fake server
+const (
+	StateQueued State = iota
+	StateRunning
+	StateComplete
+	StateFailed
+	StateCancelled
+)
+
+// String gives human readable name of state
+func (s State) String() string {
+	switch s {
+	case StateQueued:
+		return "queued"
+	case StateRunning:
+		return "running"
+	case StateComplete:
+		return "complete"
+	case StateFailed:
+		return "failed"
+	case StateCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// Behavior describes how fake server handles tests
+type Behavior struct {
+	// How long test waits in queue before it starts running
+	QueuedFor time.Duration
+	// How long test runs before it is completed
+	RunningFor time.Duration
+
+	// If set, test ends with this status code and text in testStatus.php and
+	// jsonResult.php instead of being completed
+	FailStatusCode int
+	FailStatusText string
+
+	// If set, runtest.php rejects tests with this status code and text
+	RejectStatusCode int
+	RejectStatusText string
+
+	// Raw response of jsonResult.php, like files in testdata. If empty,
+	// result is generated from test settings
+	Result []byte
+}
+
+// Test is test that was started on fake server
+type Test struct {
+	ID       string
+	OwnerKey string
+	// Form values, that were passed to runtest.php
+	Params   map[string]string
+	Behavior Behavior
+
+	Started time.Time
+	// Set by Complete or cancelTest.php, overrides state calculated from Behavior
+	forced *State
+}
+
+// snapshot returns copy of test, that shares no memory with it
+func (t *Test) snapshot() Test {
+	test := *t
+	test.Params = make(map[string]string, len(t.Params))
+	for key, value := range t.Params {
+		test.Params[key] = value
+	}
+	if t.forced != nil {
+		state := *t.forced
+		test.forced = &state
+	}
+	return test
+}
+
+// State returns state of test at given moment
+func (t *Test) State(now time.Time) State {
+	if t.forced != nil {
+		return *t.forced
+	}
+
+	elapsed := now.Sub(t.Started)
+	switch {
+	case elapsed < t.Behavior.QueuedFor:
+		return StateQueued
+	case elapsed < t.Behavior.QueuedFor+t.Behavior.RunningFor:
+		return StateRunning
+	case t.Behavior.FailStatusCode != 0:
+		return StateFailed
+	}
+	return StateComplete
+}
+
+// Server is fake WebPageTest server
+type Server struct {
+	*httptest.Server
+
+	// APIKey, if set, is required for runtest.php, like on public instance
+	APIKey string
+
+	mu        sync.Mutex
+	behavior  Behavior
+	tests     map[string]*Test
+	order     []string
+	locations []Location
+	failures  map[string][]int
+	requests  map[string]int
+}
+
+// Location is test location of fake server
+type Location struct {
+	ID       string
+	Label    string
+	Group    string
+	Browsers []string
+	// Number of idle agents
+	Idle int
+	// Number of tests waiting at location besides tests started on fake server
+	Pending int
+}
+
+// NewServer starts new fake server with one location "Test" and tests, that
+// are completed right away
+func NewServer() *Server {
+	s := &Server{
+		tests:    make(map[string]*Test),
+		failures: make(map[string][]int),
+		requests: make(map[string]int),
+		locations: []Location{{
+			ID:       "Test",
+			Label:    "Test Location",
+			Group:    "Test",
+			Browsers: []string{"Chrome", "Firefox"},
+			Idle:     1,
+		}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/runtest.php", s.handle("/runtest.php", s.runTest))
+	mux.HandleFunc("/testStatus.php", s.handle("/testStatus.php", s.testStatus))
+	mux.HandleFunc("/jsonResult.php", s.handle("/jsonResult.php", s.jsonResult))
+	mux.HandleFunc("/cancelTest.php", s.handle("/cancelTest.php", s.cancelTest))
+	mux.HandleFunc("/getLocations.php", s.handle("/getLocations.php", s.getLocations))
+	mux.HandleFunc("/getTesters.php", s.handle("/getTesters.php", s.getTesters))
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// SetBehavior sets behavior for tests that will be started after this call
+func (s *Server) SetBehavior(b Behavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.behavior = b
+}
+
+// SetLocations replaces locations of server
+func (s *Server) SetLocations(locations ...Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locations = locations
+}
+
+// FailRequests makes next requests to endpoint (like "/testStatus.php") fail
+// with given HTTP statuses, one status per request
+func (s *Server) FailRequests(endpoint string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[endpoint] = append(s.failures[endpoint], statuses...)
+}
+
+// Requests returns number of requests, that were made to endpoint
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[endpoint]
+}
+
+// Tests returns copies of all tests started on server, in order. Copies are
+// not updated by server, call Tests again to see changes
+func (s *Server) Tests() []Test {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tests := make([]Test, 0, len(s.order))
+	for _, id := range s.order {
+		tests = append(tests, s.tests[id].snapshot())
+	}
+	return tests
+}
+
+// Complete forces test to given final state, no matter what its Behavior says
+func (s *Server) Complete(testID string, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.tests[testID]
+	if !ok {
+		return fmt.Errorf("test %s not found", testID)
+	}
+	test.forced = &state
+	return nil
+}
+
+// handle wraps handler with request accounting and injected failures
+func (s *Server) handle(endpoint string, handler func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[endpoint]++
+		var status int
+		if failures := s.failures[endpoint]; len(failures) > 0 {
+			status, s.failures[endpoint] = failures[0], failures[1:]
+		}
+		s.mu.Unlock()
+
+		if status != 0 {
+			http.Error(rw, http.StatusText(status), status)
+			return
+		}
+		handler(rw, r)
+	}
+}
+
+func (s *Server) runTest(rw http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(rw, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.APIKey != "" && r.Form.Get("k") != s.APIKey {
+		writeJSON(rw, http.StatusBadRequest, "Invalid API Key", nil)
+		return
+	}
+	if r.Form.Get("url") == "" && r.Form.Get("script") == "" {
+		writeJSON(rw, http.StatusBadRequest, "Invalid URL, please try submitting your test request again.", nil)
+		return
+	}
+	if s.behavior.RejectStatusCode != 0 {
+		writeJSON(rw, s.behavior.RejectStatusCode, s.behavior.RejectStatusText, nil)
+		return
+	}
+
+	params := make(map[string]string)
+	for key := range r.Form {
+		params[key] = r.Form.Get(key)
+	}
+	test := &Test{
+		ID:       fmt.Sprintf("%s_FK_%d", time.Now().Format("060102"), len(s.order)+1),
+		OwnerKey: fmt.Sprintf("%040x", len(s.order)+1),
+		Params:   params,
+		Behavior: s.behavior,
+		Started:  time.Now(),
+	}
+	s.tests[test.ID] = test
+	s.order = append(s.order, test.ID)
+
+	writeJSON(rw, http.StatusOK, "Ok", map[string]string{
+		"testId":     test.ID,
+		"ownerKey":   test.OwnerKey,
+		"jsonUrl":    s.URL + "/jsonResult.php?test=" + test.ID,
+		"xmlUrl":     s.URL + "/xmlResult.php?test=" + test.ID,
+		"userUrl":    s.URL + "/results.php?test=" + test.ID,
+		"summaryCSV": s.URL + "/csv.php?test=" + test.ID,
+		"detailCSV":  s.URL + "/csv.php?test=" + test.ID + "&amp;requests=1",
+	})
+}
+
+func (s *Server) testStatus(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.tests[r.FormValue("test")]
+	if !ok {
+		writeJSON(rw, http.StatusBadRequest, "Test not found", nil)
+		return
+	}
+
+	now := time.Now()
+	state := test.State(now)
+	runs := testRuns(test)
+	location, browser, connectivity := splitLocation(test.Params["location"])
+	data := map[string]interface{}{
+		"id":            test.ID,
+		"testId":        test.ID,
+		"runs":          runs,
+		"fvonly":        atoi(test.Params["fvonly"], 0),
+		"location":      location,
+		"startTime":     test.Started.Format("01/02/06 15:04:05"),
+		"elapsed":       int(now.Sub(test.Started).Seconds()),
+		"testsExpected": runs,
+		"testInfo": map[string]interface{}{
+			"url":          test.Params["url"],
+			"runs":         runs,
+			"fvonly":       atoi(test.Params["fvonly"], 0),
+			"label":        test.Params["label"],
+			"location":     location,
+			"browser":      browser,
+			"connectivity": connectivity,
+			"plr":          "0",
+		},
+	}
+
+	var code int
+	var text string
+	switch state {
+	case StateQueued:
+		code, text = 101, fmt.Sprintf("Waiting behind %d other tests...", s.behind(test))
+		data["behindCount"] = s.behind(test)
+	case StateRunning:
+		code, text = 100, "Test Started"
+		data["testsCompleted"] = 0
+	case StateComplete:
+		code, text = 200, "Test Complete"
+		data["testsCompleted"] = runs
+		data["fvRunsCompleted"] = runs
+		data["completeTime"] = now.Format("01/02/06 15:04:05")
+	case StateFailed:
+		code, text = test.Behavior.FailStatusCode, test.Behavior.FailStatusText
+	case StateCancelled:
+		code, text = 402, "Test Cancelled"
+	}
+	data["statusCode"] = code
+	data["statusText"] = text
+
+	writeJSON(rw, code, text, data)
+}
+
+// behind returns number of tests in queue ahead of given one
+func (s *Server) behind(test *Test) int {
+	var behind int
+	now := time.Now()
+	for _, id := range s.order {
+		if id == test.ID {
+			break
+		}
+		if s.tests[id].State(now) == StateQueued {
+			behind++
+		}
+	}
+	return behind
+}
+
+func (s *Server) jsonResult(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.tests[r.FormValue("test")]
+	if !ok {
+		writeJSON(rw, http.StatusBadRequest, "Test not found", nil)
+		return
+	}
+
+	switch test.State(time.Now()) {
+	case StateQueued:
+		writeJSON(rw, 101, "Test Pending", nil)
+	case StateRunning:
+		writeJSON(rw, 100, "Test Started", nil)
+	case StateFailed:
+		writeJSON(rw, test.Behavior.FailStatusCode, test.Behavior.FailStatusText, nil)
+	case StateCancelled:
+		writeJSON(rw, 402, "Test Cancelled", nil)
+	default:
+		rw.Header().Set("Content-Type", "application/json")
+		if len(test.Behavior.Result) > 0 {
+			rw.Write(test.Behavior.Result)
+			return
+		}
+		writeJSON(rw, http.StatusOK, "Test Complete", generateResult(test, s.URL))
+	}
+}
+
+func (s *Server) cancelTest(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	test, ok := s.tests[r.FormValue("test")]
+	if !ok || test.State(time.Now()) != StateQueued {
+		fmt.Fprint(rw, `<h3>Sorry, the test could not be cancelled.  It may have already started or been cancelled</h3><form><input type="button" value="Back" onclick="history.back()"></form>`)
+		return
+	}
+
+	cancelled := StateCancelled
+	test.forced = &cancelled
+	fmt.Fprint(rw, `<h3 align="center">Test cancelled!</h3><form><input type="button" value="Back" onclick="history.back()"></form>`)
+}
+
+func (s *Server) getLocations(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	data := make(map[string]interface{})
+	for _, location := range s.locations {
+		queued, testing := location.Pending, 0
+		for _, id := range s.order {
+			test := s.tests[id]
+			if testLocation(test) != location.ID {
+				continue
+			}
+			switch test.State(now) {
+			case StateQueued:
+				queued++
+			case StateRunning:
+				testing++
+			}
+		}
+
+		data[location.ID] = map[string]interface{}{
+			"Label":      location.Label,
+			"labelShort": location.Label,
+			"location":   location.ID,
+			"Browsers":   strings.Join(location.Browsers, ","),
+			"status":     "OK",
+			"group":      location.Group,
+			"PendingTests": map[string]int{
+				"Total":   queued + testing,
+				"Testing": testing,
+				"Idle":    location.Idle,
+			},
+		}
+	}
+
+	writeJSON(rw, http.StatusOK, "Ok", data)
+}
+
+func (s *Server) getTesters(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := make(map[string]interface{})
+	for _, location := range s.locations {
+		testers := make([]map[string]interface{}, 0, location.Idle)
+		for i := 1; i <= location.Idle; i++ {
+			testers = append(testers, map[string]interface{}{
+				"id":      fmt.Sprintf("%s-%d", location.ID, i),
+				"pc":      fmt.Sprintf("%s-%d", location.ID, i),
+				"ip":      fmt.Sprintf("127.0.0.%d", i),
+				"version": "2.19.0.334",
+				"busy":    0,
+			})
+		}
+		data[location.ID] = map[string]interface{}{
+			"status":  "OK",
+			"testers": testers,
+		}
+	}
+
+	writeJSON(rw, http.StatusOK, "Ok", data)
+}
+
+// testLocation returns location ID of test, "Test:Chrome.Cable" becomes "Test"
+func testLocation(test *Test) string {
+	location, _, _ := splitLocation(test.Params["location"])
+	return location
+}
+
+// splitLocation splits "Dulles:Chrome.Cable" into location, browser and
+// connectivity, as they are reported in testInfo
+func splitLocation(value string) (location, browser, connectivity string) {
+	parts := strings.SplitN(value, ":", 2)
+	location = parts[0]
+	if len(parts) == 2 {
+		browser = parts[1]
+		if idx := strings.LastIndex(browser, "."); idx >= 0 {
+			browser, connectivity = browser[:idx], browser[idx+1:]
+		}
+	}
+	return location, browser, connectivity
+}
+
+// writeJSON writes response in usual for WebPageTest envelope
+func writeJSON(rw http.ResponseWriter, code int, text string, data interface{}) {
+	response := map[string]interface{}{
+		"statusCode": code,
+		"statusText": text,
+	}
+	if data != nil {
+		response["data"] = data
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(response)
+}
+
+// testRuns returns number of runs requested for test, at least one
+func testRuns(test *Test) int {
+	if runs := atoi(test.Params["runs"], 1); runs > 0 {
+		return runs
+	}
+	return 1
+}
+
+func atoi(s string, fallback int) int {
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,163 @@
+package wpttest_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/olegfedoseev/go-webpagetest"
+	"github.com/olegfedoseev/go-webpagetest/wpttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerLifecycle(t *testing.T) {
+	server := wpttest.NewServer()
+	defer server.Close()
+	server.SetBehavior(wpttest.Behavior{QueuedFor: 50 * time.Millisecond, RunningFor: 50 * time.Millisecond})
+
+	wpt, err := webpagetest.NewClient(server.URL)
+	assert.Nil(t, err)
+
+	test, err := wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com", Location: "Test:Chrome", Runs: 3})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, test.OwnerKey)
+
+	var states []int
+	result, err := wpt.WaitForTest(context.Background(), test.TestID, webpagetest.WaitOptions{
+		PollInterval: 10 * time.Millisecond,
+		OnStatus: func(event webpagetest.StatusEvent) {
+			if len(states) == 0 || states[len(states)-1] != event.StatusCode {
+				states = append(states, event.StatusCode)
+			}
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{101, 100, 200}, states)
+	assert.Equal(t, test.TestID, result.ID)
+	assert.Len(t, result.Runs, 3)
+	assert.Equal(t, 2000, result.Runs["1"].FirstView.Steps[0].LoadTime)
+
+	locations, err := wpt.GetLocations()
+	assert.Nil(t, err)
+	assert.Equal(t, "Test", (*locations)["Test"][0].Location)
+
+	testers, err := wpt.GetTesters()
+	assert.Nil(t, err)
+	assert.Len(t, (*testers)["Test"], 1)
+}
+
+func TestServerFailures(t *testing.T) {
+	server := wpttest.NewServer()
+	defer server.Close()
+	server.APIKey = "secret"
+
+	wpt, err := webpagetest.NewClient(server.URL)
+	assert.Nil(t, err)
+
+	_, err = wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com"})
+	assert.True(t, errors.Is(err, webpagetest.ErrInvalidAPIKey))
+
+	wpt, err = webpagetest.NewClient(server.URL, webpagetest.WithAPIKey("secret"))
+	assert.Nil(t, err)
+
+	// Queued test can be cancelled
+	server.SetBehavior(wpttest.Behavior{QueuedFor: time.Minute})
+	test, err := wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com"})
+	assert.Nil(t, err)
+	assert.Nil(t, wpt.CancelTest(test.TestID))
+	assert.True(t, errors.Is(wpt.CancelTest(test.TestID), webpagetest.ErrCancelFailed))
+
+	// Tests are copies, changing them does not affect server
+	tests := server.Tests()
+	assert.Len(t, tests, 1)
+	assert.Equal(t, wpttest.StateCancelled, tests[0].State(time.Now()))
+	tests[0].Params["url"] = "https://example.org"
+	assert.Equal(t, "https://example.com", server.Tests()[0].Params["url"])
+
+	// Failed test
+	server.SetBehavior(wpttest.Behavior{FailStatusCode: 404, FailStatusText: "Test failed"})
+	test, err = wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com"})
+	assert.Nil(t, err)
+	_, err = wpt.GetTestResult(test.TestID)
+	var apiErr *webpagetest.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 404, apiErr.StatusCode)
+
+	// Fixture and injected HTTP errors
+	fixture, err := ioutil.ReadFile("../testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+	server.SetBehavior(wpttest.Behavior{Result: fixture})
+	test, err = wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com"})
+	assert.Nil(t, err)
+
+	server.FailRequests("/jsonResult.php", http.StatusServiceUnavailable)
+	_, err = wpt.GetTestResult(test.TestID)
+	assert.True(t, errors.Is(err, webpagetest.ErrServer))
+
+	result, err := wpt.GetTestResult(test.TestID)
+	assert.Nil(t, err)
+	assert.Equal(t, "171113_2M_S", result.ID)
+	assert.Equal(t, 3, server.Requests("/jsonResult.php"))
+}
+
+func TestWaitToleratesPollErrors(t *testing.T) {
+	server := wpttest.NewServer()
+	defer server.Close()
+
+	wpt, err := webpagetest.NewClient(server.URL)
+	assert.Nil(t, err)
+	opts := webpagetest.WaitOptions{PollInterval: time.Millisecond}
+
+	test, err := wpt.RunTest(webpagetest.TestSettings{URL: "https://example.com"})
+	assert.Nil(t, err)
+	server.FailRequests("/testStatus.php", http.StatusBadGateway, http.StatusServiceUnavailable)
+	result, err := wpt.WaitForTest(context.Background(), test.TestID, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, test.TestID, result.ID)
+	assert.Equal(t, 3, server.Requests("/testStatus.php"))
+
+	// Too many errors in a row
+	server.FailRequests("/testStatus.php", 500, 500, 500, 500)
+	_, err = wpt.WaitForTest(context.Background(), test.TestID, opts)
+	assert.True(t, errors.Is(err, webpagetest.ErrServer))
+
+	// Test that server doesn't know about is not worth waiting for
+	_, err = wpt.WaitForTest(context.Background(), "unknown", opts)
+	assert.True(t, errors.Is(err, webpagetest.ErrTestNotFound))
+	assert.Equal(t, 8, server.Requests("/testStatus.php"))
+}
+
+func TestBatchToleratesPollErrors(t *testing.T) {
+	server := wpttest.NewServer()
+	defer server.Close()
+	server.SetBehavior(wpttest.Behavior{RunningFor: 20 * time.Millisecond})
+
+	wpt, err := webpagetest.NewClient(server.URL)
+	assert.Nil(t, err)
+
+	// Transient errors of status and result polls don't drop tests
+	server.FailRequests("/testStatus.php", http.StatusBadGateway, http.StatusGatewayTimeout)
+	server.FailRequests("/jsonResult.php", http.StatusServiceUnavailable)
+	tests := []webpagetest.TestSettings{
+		{URL: "https://example.com/1", Location: "Test:Chrome"},
+		{URL: "https://example.com/2", Location: "Test:Chrome"},
+	}
+	results, err := wpt.RunBatch(context.Background(), tests, webpagetest.BatchOptions{PollInterval: 5 * time.Millisecond})
+	assert.Nil(t, err)
+	for _, result := range results {
+		assert.Nil(t, result.Err)
+		assert.NotNil(t, result.Result)
+	}
+
+	// Test is given up after too many errors in a row
+	server.FailRequests("/testStatus.php", 500, 500, 500)
+	results, err = wpt.RunBatch(context.Background(), tests[:1], webpagetest.BatchOptions{
+		PollInterval:  5 * time.Millisecond,
+		MaxPollErrors: 2,
+	})
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(results[0].Err, webpagetest.ErrServer))
+}